@@ -8,7 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"os"
@@ -21,17 +21,20 @@ import (
 
 // Api represents the MAX Bot API client.
 type Api struct {
-	Bots          *bots
-	Chats         *chats
-	Debugs        *debugs
-	Messages      *messages
-	Subscriptions *subscriptions
-	Uploads       *uploads
-
-	client  *client
-	timeout time.Duration
-	pause   time.Duration
-	debug   bool
+	Bots          BotsAPI
+	Chats         ChatsAPI
+	Debugs        DebugsAPI
+	Messages      MessagesAPI
+	Subscriptions SubscriptionsAPI
+	Uploads       UploadsAPI
+
+	client   *client
+	messages *messages // sub-clients created by New, configured by the setters of Api
+	uploads  *uploads
+	timeout  time.Duration
+	pause    time.Duration
+	debug    bool
+	journal  *JournalWriter
 }
 
 // New creates a new Max Bot API client with the provided token.
@@ -59,8 +62,10 @@ func New(token string) (*Api, error) {
 	// Initialize sub-clients
 	api.Bots = newBots(cl)
 	api.Chats = newChats(cl)
-	api.Uploads = newUploads(cl)
-	api.Messages = newMessages(cl)
+	api.uploads = newUploads(cl)
+	api.Uploads = api.uploads
+	api.messages = newMessages(cl)
+	api.Messages = api.messages
 	api.Subscriptions = newSubscriptions(cl)
 	api.Debugs = newDebugs(cl, 0)
 
@@ -115,14 +120,52 @@ func NewWithConfig(cfg configservice.ConfigInterface) (*Api, error) {
 	// Initialize sub-clients
 	api.Bots = newBots(cl)
 	api.Chats = newChats(cl)
-	api.Uploads = newUploads(cl)
-	api.Messages = newMessages(cl)
+	api.uploads = newUploads(cl)
+	api.Uploads = api.uploads
+	api.messages = newMessages(cl)
+	api.Messages = api.messages
 	api.Subscriptions = newSubscriptions(cl)
 	api.Debugs = newDebugs(cl, cfg.GetDebugLogChat())
 
 	return api, nil
 }
 
+// SetLogger sets the logger of the client and all its sub-clients. Requests and responses are logged at the debug
+// level with method, path, status, latency and the redacted token. By default nothing is logged except the requests
+// skipped in the dry-run mode, which go to slog.Default(); nil restores that.
+func (a *Api) SetLogger(logger *slog.Logger) {
+	a.client.loggerSet = logger != nil
+	if logger == nil {
+		logger = slog.New(slog.DiscardHandler)
+	}
+	a.client.logger = logger
+}
+
+// SetUploadCache sets the cache used by all uploads to reuse tokens of the content uploaded before.
+// Only seekable readers, e.g. files, are looked up in the cache: the content is hashed before the upload.
+// Like the other settings of the sub-clients, it applies to the clients created by New, not to replacements of Api fields.
+func (a *Api) SetUploadCache(cache UploadCache) {
+	a.uploads.setCache(cache)
+}
+
+// SetUploadSizeLimit sets the size of the largest file of the type uploaded, larger files fail before any network call.
+// There are no limits by default, as the API does not publish them. Zero or negative limit disables the check.
+func (a *Api) SetUploadSizeLimit(uploadType schemes.UploadType, limit int64) {
+	a.uploads.setSizeLimit(uploadType, limit)
+}
+
+// SetAttachmentRetry sets how many times SendWhenReady resends the message while the attachments are processed,
+// and the initial pause between attempts, doubled after every attempt up to 8 seconds.
+// Zero or negative pause means the default of 500ms.
+func (a *Api) SetAttachmentRetry(retries int, wait time.Duration) {
+	a.messages.setAttachmentRetry(retries, wait)
+}
+
+// SetTransport sets the transport of the HTTP client used for all requests, e.g. Recorder or Replayer.
+func (a *Api) SetTransport(transport http.RoundTripper) {
+	a.client.httpClient.Transport = transport
+}
+
 func getUpdateType(updateType schemes.UpdateType) func(debugRaw string) schemes.UpdateInterface {
 	switch updateType {
 	case schemes.TypeMessageCallback:
@@ -221,6 +264,7 @@ func (a *Api) bytesToProperUpdate(data []byte) (schemes.UpdateInterface, error)
 	if err := a.processMessageAttachments(update); err != nil {
 		return nil, fmt.Errorf("failed to process message attachments: %w", err)
 	}
+	a.client.checkUnknownFields(data, update)
 
 	return update, nil
 }
@@ -269,6 +313,15 @@ func (a *Api) processMessageAttachments(update schemes.UpdateInterface) error {
 
 			u.Message.Link.Message.Attachments = append(u.Message.Link.Message.Attachments, attachments...)
 		}
+	case *schemes.MessageCallbackUpdate:
+		if u.Message != nil && u.Message.Body.RawAttachments != nil {
+			attachments, err := a.convertRawAttachments(u.Message.Body.RawAttachments)
+			if err != nil {
+				return err
+			}
+
+			u.Message.Body.Attachments = append(u.Message.Body.Attachments, attachments...)
+		}
 	default:
 		return nil // No attachments to process
 	}
@@ -357,7 +410,7 @@ func (a *Api) getUpdates(ctx context.Context, params *UpdatesParams) (*schemes.U
 
 	defer func() {
 		if closeErr := body.Close(); closeErr != nil {
-			log.Printf("failed to close response body: %v", closeErr)
+			a.client.logger.Error("failed to close response body", "error", closeErr)
 		}
 	}()
 
@@ -383,7 +436,9 @@ func (a *Api) getUpdatesWithRetry(ctx context.Context, params *UpdatesParams) (*
 	var lastErr error
 
 	for attempt := 0; attempt < maxRetries; attempt++ {
+		start := time.Now()
 		result, lastErr = a.getUpdates(ctx, params)
+		a.client.observePoll(time.Since(start), result, lastErr)
 		if lastErr == nil {
 			return result, nil
 		}
@@ -395,7 +450,7 @@ func (a *Api) getUpdatesWithRetry(ctx context.Context, params *UpdatesParams) (*
 
 		if attempt < maxRetries-1 {
 			retryWait := time.Duration(1<<uint(attempt)) * time.Second
-			log.Printf("Attempt %d failed, retrying in %v: %v", attempt+1, retryWait, lastErr)
+			a.client.logger.Warn("failed to get updates, retrying", "attempt", attempt+1, "wait", retryWait, "error", lastErr)
 			select {
 			case <-ctx.Done():
 				return nil, ctx.Err()
@@ -432,7 +487,7 @@ func (a *Api) GetUpdates(ctx context.Context) <-chan schemes.UpdateInterface {
 
 					updateList, err := a.getUpdatesWithRetry(ctx, params)
 					if err != nil {
-						log.Printf("failed to get updates: %v", err)
+						a.client.logger.Error("failed to get updates", "error", err)
 						break
 					}
 
@@ -441,8 +496,11 @@ func (a *Api) GetUpdates(ctx context.Context) <-chan schemes.UpdateInterface {
 					}
 
 					for _, rawUpdate := range updateList.Updates {
+						a.record(rawUpdate)
 						update, err := a.bytesToProperUpdate(rawUpdate)
+						a.client.observeUpdate(rawUpdate, update, err)
 						if err != nil {
+							a.client.logger.Warn("failed to parse update", "error", err)
 							continue
 						}
 
@@ -478,7 +536,9 @@ func (a *Api) GetHandler(updates chan<- schemes.UpdateInterface) http.HandlerFun
 			return
 		}
 
+		a.record(body)
 		update, err := a.bytesToProperUpdate(body)
+		a.client.observeUpdate(body, update, err)
 		if err != nil {
 			http.Error(w, "Failed to parse update", http.StatusBadRequest)
 			return
@@ -5,8 +5,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"reflect"
 	"strconv"
 	"testing"
@@ -307,3 +310,62 @@ func TestGetHandler(t *testing.T) {
 		t.Error("no update received")
 	}
 }
+
+func TestSetLogger(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/chats/2" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":"chat.not.found","message":"Chat 2 not found"}`))
+			return
+		}
+		w.Write([]byte(`{"chat_id":1}`))
+	}))
+	defer server.Close()
+
+	var global bytes.Buffer
+	log.SetOutput(&global)
+	defer log.SetOutput(os.Stderr)
+
+	api := newTestApiWithToken(t, "secret-bot-token", server.URL)
+
+	// Nothing is logged by default.
+	_, err := api.Chats.GetChat(context.Background(), 1)
+	require.NoError(t, err)
+	require.Empty(t, global.String())
+
+	tests := []struct {
+		name  string
+		level slog.Level
+		want  []string
+	}{
+		{
+			name:  "debug",
+			level: slog.LevelDebug,
+			want:  []string{"msg=request method=GET", "path=/chats/1", "status=200", "latency=", "token=secr...REDACTED", "path=/chats/2 latency="},
+		},
+		{
+			name:  "info",
+			level: slog.LevelInfo,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			api.SetLogger(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: tt.level})))
+
+			_, err := api.Chats.GetChat(context.Background(), 1)
+			require.NoError(t, err)
+			_, err = api.Chats.GetChat(context.Background(), 2)
+			require.ErrorIs(t, err, &APIError{Code: http.StatusNotFound})
+
+			for _, want := range tt.want {
+				require.Contains(t, logs.String(), want)
+			}
+			if len(tt.want) == 0 {
+				require.Empty(t, logs.String())
+			}
+			require.NotContains(t, logs.String(), "secret-bot-token")
+		})
+	}
+	require.Empty(t, global.String())
+}
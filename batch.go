@@ -0,0 +1,131 @@
+package maxbot
+
+import (
+	"context"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/pavmos/max-bot-api-client-go/schemes"
+)
+
+// UploadItem is a file or a reader uploaded by UploadMany.
+type UploadItem struct {
+	Type   schemes.UploadType // Detected from the content if empty
+	Path   string             // File to upload, used when Reader is nil
+	Reader io.Reader          // Content to upload
+	Name   string             // File name sent to the server, Path by default
+}
+
+// UploadFile returns the item uploading the file.
+func UploadFile(uploadType schemes.UploadType, path string) UploadItem {
+	return UploadItem{Type: uploadType, Path: path}
+}
+
+// UploadReader returns the item uploading the content of the reader.
+func UploadReader(uploadType schemes.UploadType, reader io.Reader, name string) UploadItem {
+	return UploadItem{Type: uploadType, Reader: reader, Name: name}
+}
+
+func (item UploadItem) name() string {
+	if item.Name != "" {
+		return item.Name
+	}
+
+	return item.Path
+}
+
+// UploadMany uploads the items concurrently, by WithWorkers at once, and returns the attachment requests
+// in the order of the items, ready for Message.AddAlbum. Failed items are nil in the result and reported
+// together by *BatchUploadError, so the successful uploads are not lost. Other options apply to every item;
+// the progress function is called for each of them and must be safe for concurrent use.
+func (a *uploads) UploadMany(ctx context.Context, items []UploadItem, opts ...UploadOption) ([]any, error) {
+	options := newUploadOptions(opts)
+	attachments := make([]any, len(items))
+	errs := make([]error, len(items))
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(options.workers, len(items)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				attachments[i], errs[i] = a.uploadItem(ctx, items[i], opts)
+			}
+		}()
+	}
+
+	for i := range items {
+		if ctx.Err() != nil {
+			errs[i] = ctx.Err()
+			continue
+		}
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	var batchErr BatchUploadError
+	for i, err := range errs {
+		if err != nil {
+			batchErr.Errors = append(batchErr.Errors, &UploadItemError{Index: i, Name: items[i].name(), Err: err})
+		}
+	}
+	if len(batchErr.Errors) > 0 {
+		return attachments, &batchErr
+	}
+
+	return attachments, nil
+}
+
+// UploadAuto uploads the file as a photo, video, audio or file depending on its content
+// and returns the request attaching it, ready for Message.AddAttachment.
+func (a *uploads) UploadAuto(ctx context.Context, filename string, opts ...UploadOption) (any, error) {
+	return a.uploadItem(ctx, UploadFile("", filename), opts)
+}
+
+// uploadItem uploads the item and returns the request attaching it.
+func (a *uploads) uploadItem(ctx context.Context, item UploadItem, opts []UploadOption) (any, error) {
+	reader := item.Reader
+	if reader == nil {
+		fh, err := os.Open(item.Path)
+		if err != nil {
+			return nil, err
+		}
+		defer fh.Close()
+		reader = fh
+	}
+
+	if item.Type == "" {
+		head, peeked, err := peekHead(reader)
+		if err != nil {
+			return nil, err
+		}
+		item.Type, _ = DetectUploadType(head, item.name())
+		reader = peeked
+	}
+
+	if item.Type == schemes.PHOTO {
+		photo, err := a.UploadPhotoFromReaderWithName(ctx, reader, item.name(), opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		return schemes.NewPhotoAttachmentRequest(schemes.PhotoAttachmentRequestPayload{Photos: photo.Photos}), nil
+	}
+
+	info, err := a.UploadMediaFromReaderWithName(ctx, item.Type, reader, item.name(), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	switch item.Type {
+	case schemes.VIDEO:
+		return schemes.NewVideoAttachmentRequest(*info), nil
+	case schemes.AUDIO:
+		return schemes.NewAudioAttachmentRequest(*info), nil
+	default:
+		return schemes.NewFileAttachmentRequest(*info), nil
+	}
+}
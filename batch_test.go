@@ -0,0 +1,78 @@
+package maxbot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavmos/max-bot-api-client-go/schemes"
+)
+
+func TestUploadMany(t *testing.T) {
+	var active, peak atomic.Int32
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("/uploads", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(schemes.UploadEndpoint{Url: server.URL + "/upload"})
+	})
+	mux.HandleFunc("/upload", func(w http.ResponseWriter, r *http.Request) {
+		n := active.Add(1)
+		defer active.Add(-1)
+		for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		_, header, err := r.FormFile(uploadFormField)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(schemes.UploadedInfo{Token: filepath.Base(header.Filename)})
+	})
+
+	api := newTestApi(t, server.URL)
+	dir := t.TempDir()
+
+	var items []UploadItem
+	for i := range 5 {
+		name := filepath.Join(dir, fmt.Sprintf("%d.mp4", i))
+		require.NoError(t, os.WriteFile(name, append([]byte{0}, name...), 0o600)) // binary content of a distinct hash
+		items = append(items, UploadFile(schemes.VIDEO, name))
+	}
+	items = append(items,
+		UploadFile(schemes.VIDEO, filepath.Join(dir, "missing.mp4")),
+		UploadReader(schemes.FILE, strings.NewReader("doc"), "doc.pdf"),
+	)
+
+	attachments, err := api.Uploads.UploadMany(context.Background(), items, WithWorkers(2))
+
+	var batchErr *BatchUploadError
+	require.ErrorAs(t, err, &batchErr)
+	require.Len(t, batchErr.Errors, 1)
+	require.Equal(t, 5, batchErr.Errors[0].Index)
+	require.True(t, errors.Is(err, os.ErrNotExist))
+
+	require.Len(t, attachments, len(items))
+	for i := range 5 {
+		require.Equal(t, schemes.NewVideoAttachmentRequest(schemes.UploadedInfo{Token: fmt.Sprintf("%d.mp4", i)}), attachments[i])
+	}
+	require.Nil(t, attachments[5])
+	require.Equal(t, schemes.NewFileAttachmentRequest(schemes.UploadedInfo{Token: "doc.pdf"}), attachments[6])
+	require.LessOrEqual(t, peak.Load(), int32(2))
+
+	msg := NewMessage().AddAlbum(attachments)
+	require.Len(t, msg.message.Attachments, 6)
+}
@@ -2,8 +2,6 @@ package maxbot
 
 import (
 	"context"
-	"encoding/json"
-	"log"
 	"net/http"
 	"net/url"
 
@@ -30,11 +28,11 @@ func (a *bots) GetBot(ctx context.Context) (*schemes.BotInfo, error) {
 	}
 	defer func() {
 		if err := body.Close(); err != nil {
-			log.Println(err)
+			a.client.logger.Error("failed to close response body", "error", err)
 		}
 	}()
 
-	return result, json.NewDecoder(body).Decode(result)
+	return result, a.client.decode(body, result)
 }
 
 // PatchBot edits current bot info. Fill only the fields you want to update. All remaining fields will stay untouched.
@@ -48,9 +46,9 @@ func (a *bots) PatchBot(ctx context.Context, patch *schemes.BotPatch) (*schemes.
 	}
 	defer func() {
 		if err := body.Close(); err != nil {
-			log.Println(err)
+			a.client.logger.Error("failed to close response body", "error", err)
 		}
 	}()
 
-	return result, json.NewDecoder(body).Decode(result)
+	return result, a.client.decode(body, result)
 }
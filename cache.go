@@ -0,0 +1,191 @@
+package maxbot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pavmos/max-bot-api-client-go/schemes"
+)
+
+// UploadCache stores results of uploads (PhotoTokens or UploadedInfo encoded as JSON) by the content hash,
+// so sending the same content again reuses the attachment tokens instead of uploading it.
+// Tokens are valid only for the bot that has uploaded the content, so keys include a hash of the bot token
+// and one cache can be shared by several bots.
+type UploadCache interface {
+	// Get returns the stored result, or false if there is none or it has expired.
+	Get(key string) ([]byte, bool)
+	// Set stores the result.
+	Set(key string, value []byte) error
+}
+
+type uploadCacheEntry struct {
+	Value   json.RawMessage `json:"value"`
+	Expires time.Time       `json:"expires,omitempty"`
+}
+
+func (e uploadCacheEntry) expired(now time.Time) bool {
+	return !e.Expires.IsZero() && now.After(e.Expires)
+}
+
+// MemoryUploadCache keeps upload results in memory.
+type MemoryUploadCache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]uploadCacheEntry
+}
+
+// NewMemoryUploadCache returns an in-memory cache. Results expire after ttl, zero ttl means they never expire.
+func NewMemoryUploadCache(ttl time.Duration) *MemoryUploadCache {
+	return &MemoryUploadCache{
+		ttl:     ttl,
+		entries: make(map[string]uploadCacheEntry),
+	}
+}
+
+func (c *MemoryUploadCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if entry.expired(time.Now()) {
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	return entry.Value, true
+}
+
+func (c *MemoryUploadCache) Set(key string, value []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = newUploadCacheEntry(value, c.ttl)
+
+	return nil
+}
+
+// FileUploadCache keeps upload results in a JSON file, so they survive restarts.
+type FileUploadCache struct {
+	path string
+	ttl  time.Duration
+
+	mu      sync.Mutex
+	entries map[string]uploadCacheEntry
+}
+
+// NewFileUploadCache returns a cache stored in the file at path, loading results saved earlier.
+// Results expire after ttl, zero ttl means they never expire.
+func NewFileUploadCache(path string, ttl time.Duration) (*FileUploadCache, error) {
+	c := &FileUploadCache{
+		path:    path,
+		ttl:     ttl,
+		entries: make(map[string]uploadCacheEntry),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &c.entries); err != nil {
+		return nil, &SerializationError{Op: "unmarshal", Type: "upload cache", Err: err}
+	}
+
+	now := time.Now()
+	for key, entry := range c.entries {
+		if entry.expired(now) {
+			delete(c.entries, key)
+		}
+	}
+
+	return c, nil
+}
+
+func (c *FileUploadCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || entry.expired(time.Now()) {
+		return nil, false
+	}
+
+	return entry.Value, true
+}
+
+func (c *FileUploadCache) Set(key string, value []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = newUploadCacheEntry(value, c.ttl)
+
+	return c.save()
+}
+
+// save writes the entries to a temporary file and renames it, so the cache file is never left half-written.
+func (c *FileUploadCache) save() error {
+	data, err := json.Marshal(c.entries)
+	if err != nil {
+		return &SerializationError{Op: "marshal", Type: "upload cache", Err: err}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), c.path)
+}
+
+func newUploadCacheEntry(value []byte, ttl time.Duration) uploadCacheEntry {
+	entry := uploadCacheEntry{Value: append(json.RawMessage(nil), value...)}
+	if ttl > 0 {
+		entry.Expires = time.Now().Add(ttl)
+	}
+
+	return entry
+}
+
+// uploadCacheKey returns the key of the content for the upload type and the bot: the prefix of the SHA-256
+// of the bot token and the SHA-256 of the content. The reader is rewound to the position it has been at.
+func uploadCacheKey(token string, uploadType schemes.UploadType, reader io.ReadSeeker) (string, error) {
+	offset, err := reader.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, reader); err != nil {
+		return "", err
+	}
+
+	if _, err := reader.Seek(offset, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	bot := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(bot[:8]) + ":" + string(uploadType) + ":" + hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,175 @@
+package maxbot
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/pavmos/max-bot-api-client-go/schemes"
+)
+
+const defaultCallbackAckTimeout = 3 * time.Second
+
+// CallbackReply answers a pressed button. It reuses the original message of the update,
+// so the handler changes only what it needs: text, keyboard or a one-time notification.
+// The first change is sent as the answer to the callback, next changes edit the original message.
+type CallbackReply struct {
+	messages MessagesAPI
+	update   *schemes.MessageCallbackUpdate
+	client   *client
+
+	mu       sync.Mutex
+	answered bool
+}
+
+// NewCallbackReply returns a reply helper for the callback update answering it with the messages client,
+// usually api.Messages or its mock.
+func NewCallbackReply(api MessagesAPI, upd *schemes.MessageCallbackUpdate) *CallbackReply {
+	r := &CallbackReply{messages: api, update: upd}
+	if m, ok := api.(*messages); ok {
+		r.client = m.client // handler metrics are reported only by the real client
+	}
+
+	return r
+}
+
+// Answered reports whether the callback has been answered.
+func (r *CallbackReply) Answered() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.answered
+}
+
+// Ack acknowledges the callback without changes, so the client stops waiting. It does nothing if the callback has been answered.
+func (r *CallbackReply) Ack(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.answered {
+		return nil
+	}
+
+	return r.answer(ctx, &schemes.CallbackAnswer{})
+}
+
+// Notify shows a one-time notification to the user.
+func (r *CallbackReply) Notify(ctx context.Context, text string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.answered {
+		return ErrCallbackAnswered
+	}
+
+	return r.answer(ctx, &schemes.CallbackAnswer{Notification: text})
+}
+
+// EditText replaces the text of the original message keeping its attachments and keyboard.
+func (r *CallbackReply) EditText(ctx context.Context, text string) error {
+	m, err := r.original()
+	if err != nil {
+		return err
+	}
+
+	m.message.Text = text
+	m.message.Markups = nil
+
+	return r.Edit(ctx, m)
+}
+
+// ReplaceKeyboard replaces the keyboard of the original message keeping its text and other attachments.
+func (r *CallbackReply) ReplaceKeyboard(ctx context.Context, keyboard *Keyboard) error {
+	m, err := r.original()
+	if err != nil {
+		return err
+	}
+
+	return r.Edit(ctx, withoutKeyboard(m).AddKeyboard(keyboard))
+}
+
+// RemoveKeyboard removes the keyboard from the original message.
+func (r *CallbackReply) RemoveKeyboard(ctx context.Context) error {
+	m, err := r.original()
+	if err != nil {
+		return err
+	}
+
+	return r.Edit(ctx, withoutKeyboard(m))
+}
+
+// Edit replaces the original message with the message built by the caller.
+func (r *CallbackReply) Edit(ctx context.Context, m *Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.answered {
+		return r.answer(ctx, &schemes.CallbackAnswer{Message: m.message})
+	}
+
+	if r.update.Message == nil {
+		return ErrCallbackNoMessage
+	}
+
+	return r.messages.EditMessage(ctx, r.update.Message.Body.Mid, m)
+}
+
+// Handle runs fn and guarantees that the callback is answered. If fn has not answered within timeout,
+// the callback is acknowledged so the client does not show an error; further changes made by fn edit the message.
+// If fn returns without answering, the callback is acknowledged as well. Zero timeout means the default.
+func (r *CallbackReply) Handle(ctx context.Context, timeout time.Duration, fn func(ctx context.Context, r *CallbackReply) error) error {
+	if timeout <= 0 {
+		timeout = defaultCallbackAckTimeout
+	}
+
+	timer := time.AfterFunc(timeout, func() {
+		_ = r.Ack(context.WithoutCancel(ctx))
+	})
+
+	err := r.client.observeHandler(callbackHandler, func() error {
+		return fn(ctx, r)
+	})
+	timer.Stop()
+
+	if ackErr := r.Ack(ctx); ackErr != nil {
+		return errors.Join(err, ackErr)
+	}
+
+	return err
+}
+
+func (r *CallbackReply) answer(ctx context.Context, answer *schemes.CallbackAnswer) error {
+	result, err := r.messages.AnswerOnCallback(ctx, r.update.Callback.CallbackID, answer)
+	if err != nil {
+		return err
+	}
+	if !result.Success {
+		return errors.New(result.Message)
+	}
+	r.answered = true
+
+	return nil
+}
+
+// original returns the original message of the callback. It fails if some attachments cannot be sent again,
+// so that editing the message does not drop them.
+func (r *CallbackReply) original() (*Message, error) {
+	if r.update.Message == nil {
+		return nil, ErrCallbackNoMessage
+	}
+
+	return newMessageFrom(*r.update.Message)
+}
+
+func withoutKeyboard(m *Message) *Message {
+	attachments := m.message.Attachments[:0]
+	for _, a := range m.message.Attachments {
+		if _, ok := a.(*schemes.InlineKeyboardAttachmentRequest); !ok {
+			attachments = append(attachments, a)
+		}
+	}
+	m.message.Attachments = attachments
+
+	return m
+}
@@ -0,0 +1,138 @@
+package maxbot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavmos/max-bot-api-client-go/schemes"
+)
+
+func TestCallbackReply(t *testing.T) {
+	var mu sync.Mutex
+	var answers []schemes.CallbackAnswer
+	var edits []schemes.NewMessageBody
+	var callbackIDs, messageIDs []string
+
+	// The handler only records the requests: require must not be called outside the test goroutine.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/answers":
+			answer := schemes.CallbackAnswer{}
+			if err := json.NewDecoder(r.Body).Decode(&answer); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			callbackIDs = append(callbackIDs, r.URL.Query().Get(paramCallbackID))
+			answers = append(answers, answer)
+		case r.Method == http.MethodPut && r.URL.Path == "/messages":
+			body := schemes.NewMessageBody{}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			messageIDs = append(messageIDs, r.URL.Query().Get(paramMessageID))
+			edits = append(edits, body)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+
+		json.NewEncoder(w).Encode(schemes.SimpleQueryResult{Success: true})
+	}))
+	defer server.Close()
+
+	api := newTestApi(t, server.URL)
+
+	data := []byte(`{"update_type":"message_callback","timestamp":1,"callback":{"callback_id":"cb1","payload":"next"},
+		"message":{"recipient":{"chat_id":10},"body":{"mid":"mid1","text":"Pick one","attachments":[
+		{"type":"inline_keyboard","payload":{"buttons":[[{"type":"callback","text":"Next","payload":"next"}]]}}]}}}`)
+	update, err := api.bytesToProperUpdate(data)
+	require.NoError(t, err)
+	upd := update.(*schemes.MessageCallbackUpdate)
+
+	reset := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		answers, edits = nil, nil
+		callbackIDs, messageIDs = nil, nil
+	}
+
+	t.Run("edit text keeps keyboard", func(t *testing.T) {
+		reset()
+
+		err := NewCallbackReply(api.Messages, upd).EditText(context.Background(), "Done")
+		require.NoError(t, err)
+
+		mu.Lock()
+		defer mu.Unlock()
+		require.Equal(t, []string{"cb1"}, callbackIDs)
+		require.Equal(t, "Done", answers[0].Message.Text)
+		require.Len(t, answers[0].Message.Attachments, 1)
+	})
+
+	t.Run("contact and share attachments are kept", func(t *testing.T) {
+		reset()
+
+		data := []byte(`{"update_type":"message_callback","timestamp":1,"callback":{"callback_id":"cb1","payload":"next"},
+			"message":{"recipient":{"chat_id":10},"body":{"mid":"mid1","text":"Card","attachments":[
+			{"type":"contact","payload":{"vcf_info":"BEGIN:VCARD","max_info":{"user_id":7,"name":"Anna"}}},
+			{"type":"share","payload":{"url":"https://example.com"}},
+			{"type":"inline_keyboard","payload":{"buttons":[[{"type":"callback","text":"Next","payload":"next"}]]}}]}}}`)
+		update, err := api.bytesToProperUpdate(data)
+		require.NoError(t, err)
+
+		require.NoError(t, NewCallbackReply(api.Messages, update.(*schemes.MessageCallbackUpdate)).RemoveKeyboard(context.Background()))
+
+		mu.Lock()
+		defer mu.Unlock()
+		require.Len(t, answers, 1)
+		attachments, err := json.Marshal(answers[0].Message.Attachments)
+		require.NoError(t, err)
+		require.JSONEq(t, `[
+			{"type":"contact","payload":{"name":"Anna","contact_id":7,"vcf_info":"BEGIN:VCARD"}},
+			{"type":"share","payload":{"url":"https://example.com"}}]`, string(attachments))
+	})
+
+	t.Run("attachments that cannot be resent are not dropped", func(t *testing.T) {
+		reset()
+
+		unknown := *upd
+		message := *upd.Message
+		message.Body.Attachments = append([]any{&schemes.Attachment{Type: "poll"}}, message.Body.Attachments...)
+		unknown.Message = &message
+
+		err := NewCallbackReply(api.Messages, &unknown).EditText(context.Background(), "Done")
+		require.ErrorIs(t, err, ErrAttachmentNotResendable)
+
+		mu.Lock()
+		defer mu.Unlock()
+		require.Empty(t, answers)
+	})
+
+	t.Run("handle acknowledges slow handler", func(t *testing.T) {
+		reset()
+
+		err := NewCallbackReply(api.Messages, upd).Handle(context.Background(), 10*time.Millisecond,
+			func(ctx context.Context, r *CallbackReply) error {
+				require.Eventually(t, r.Answered, time.Second, 5*time.Millisecond)
+				return r.RemoveKeyboard(ctx)
+			})
+		require.NoError(t, err)
+
+		mu.Lock()
+		defer mu.Unlock()
+		require.Equal(t, []string{"cb1"}, callbackIDs)
+		require.Nil(t, answers[0].Message)
+		require.Equal(t, []string{"mid1"}, messageIDs)
+		require.Empty(t, edits[0].Attachments)
+	})
+}
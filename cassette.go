@@ -0,0 +1,256 @@
+package maxbot
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+const redacted = "REDACTED"
+
+// Interaction is a recorded HTTP exchange.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a request of the interaction with the Authorization header and the access_token parameter redacted.
+type RecordedRequest struct {
+	Method string       `json:"method"`
+	Path   string       `json:"path"`
+	Query  string       `json:"query,omitempty"` // Normalized: sorted by key
+	Header http.Header  `json:"header,omitempty"`
+	Body   RecordedBody `json:"body,omitempty"`
+}
+
+// RecordedResponse is a response of the interaction.
+type RecordedResponse struct {
+	StatusCode int          `json:"status_code"`
+	Header     http.Header  `json:"header,omitempty"`
+	Body       RecordedBody `json:"body,omitempty"`
+}
+
+// RecordedBody is a body kept as text if it is valid UTF-8, e.g. JSON, or as base64 otherwise.
+type RecordedBody []byte
+
+func (b RecordedBody) MarshalJSON() ([]byte, error) {
+	if utf8.Valid(b) {
+		return json.Marshal(string(b))
+	}
+
+	return json.Marshal(map[string]string{"base64": base64.StdEncoding.EncodeToString(b)})
+}
+
+func (b *RecordedBody) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*b = RecordedBody(text)
+		return nil
+	}
+
+	var encoded struct {
+		Base64 string `json:"base64"`
+	}
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+	decoded, err := base64.StdEncoding.DecodeString(encoded.Base64)
+	if err != nil {
+		return err
+	}
+	*b = decoded
+
+	return nil
+}
+
+// Cassette is a sequence of HTTP interactions recorded by Recorder and replayed by Replayer.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// LoadCassette reads the cassette saved by Cassette.Save.
+func LoadCassette(filename string) (*Cassette, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	cassette := new(Cassette)
+	if err := json.Unmarshal(data, cassette); err != nil {
+		return nil, &SerializationError{Op: "unmarshal", Type: "cassette", Err: err}
+	}
+
+	return cassette, nil
+}
+
+// Save writes the cassette to the file as indented JSON.
+func (c *Cassette) Save(filename string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return &SerializationError{Op: "marshal", Type: "cassette", Err: err}
+	}
+
+	return os.WriteFile(filename, data, 0o600)
+}
+
+// Recorder is an http.RoundTripper recording all exchanges passing through it:
+//
+//	recorder := maxbot.NewRecorder(nil)
+//	api.SetTransport(recorder)
+//	...
+//	err := recorder.Cassette().Save("testdata/send.json")
+type Recorder struct {
+	transport http.RoundTripper
+
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecorder returns a recorder sending requests with the transport, http.DefaultTransport if it is nil.
+func NewRecorder(transport http.RoundTripper) *Recorder {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	return &Recorder{transport: transport}
+}
+
+// RoundTrip sends the request and records the exchange. Failed exchanges are not recorded.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		data, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		reqBody = data
+
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(data))
+	}
+
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	header := req.Header.Clone()
+	if header.Get("Authorization") != "" {
+		header.Set("Authorization", redacted)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			Path:   req.URL.Path,
+			Query:  normalizeQuery(redactQuery(req.URL.Query())),
+			Header: header,
+			Body:   reqBody,
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header.Clone(),
+			Body:       respBody,
+		},
+	})
+
+	return resp, nil
+}
+
+// Cassette returns a copy of the interactions recorded so far.
+func (r *Recorder) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return &Cassette{Interactions: append([]Interaction(nil), r.cassette.Interactions...)}
+}
+
+// Replayer is an http.RoundTripper answering requests with the recorded responses without network access.
+// A request matches an interaction by method, path and normalized query; each interaction is replayed once,
+// in the recorded order among the matching ones. A request without a matching interaction fails with ErrCassetteNoMatch.
+type Replayer struct {
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewReplayer returns a replayer of the cassette.
+func NewReplayer(cassette *Cassette) *Replayer {
+	return &Replayer{
+		interactions: cassette.Interactions,
+		used:         make([]bool, len(cassette.Interactions)),
+	}
+}
+
+// RoundTrip returns the response of the first unused interaction matching the request.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	query := normalizeQuery(redactQuery(req.URL.Query()))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.interactions {
+		recorded := interaction.Request
+		if r.used[i] || recorded.Method != req.Method || recorded.Path != req.URL.Path || recorded.Query != query {
+			continue
+		}
+		r.used[i] = true
+
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        interaction.Response.Header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader(interaction.Response.Body)),
+			ContentLength: int64(len(interaction.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s %s", ErrCassetteNoMatch, req.Method, strings.TrimSuffix(req.URL.Path+"?"+query, "?"))
+}
+
+// Remaining returns the number of interactions not replayed yet.
+func (r *Replayer) Remaining() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	remaining := 0
+	for _, used := range r.used {
+		if !used {
+			remaining++
+		}
+	}
+
+	return remaining
+}
+
+// normalizeQuery encodes the query sorted by key, so the order of parameters does not affect matching.
+func normalizeQuery(query url.Values) string {
+	return query.Encode()
+}
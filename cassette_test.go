@@ -0,0 +1,137 @@
+package maxbot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavmos/max-bot-api-client-go/schemes"
+)
+
+func newCassetteTestApi(t *testing.T, serverURL string, transport http.RoundTripper) *Api {
+	t.Helper()
+
+	api := newTestApiWithToken(t, "secret-token", serverURL)
+	api.SetTransport(transport)
+
+	return api
+}
+
+func TestCassette(t *testing.T) {
+	var authorization string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /me", func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		json.NewEncoder(w).Encode(schemes.BotInfo{UserId: 1, Name: "bot"})
+	})
+	mux.HandleFunc("POST /messages", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(schemes.SendMessageResult{Message: schemes.Message{Body: schemes.MessageBody{Mid: "mid." + r.URL.Query().Get("chat_id")}}})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	ctx := context.Background()
+	recorder := NewRecorder(nil)
+	api := newCassetteTestApi(t, server.URL, recorder)
+
+	bot, err := api.Bots.GetBot(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "bot", bot.Name)
+	require.Equal(t, "secret-token", authorization, "the token reaches the server")
+	for _, chatID := range []int64{10, 20} {
+		require.NoError(t, api.Messages.Send(ctx, NewMessage().SetChat(chatID).SetText("hello")))
+	}
+
+	fileName := filepath.Join(t.TempDir(), "cassette.json")
+	require.NoError(t, recorder.Cassette().Save(fileName))
+	data, err := os.ReadFile(fileName)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "secret-token")
+	require.Contains(t, string(data), redacted)
+
+	cassette, err := LoadCassette(fileName)
+	require.NoError(t, err)
+	require.Len(t, cassette.Interactions, 3)
+	require.Equal(t, "chat_id=10&v="+version, cassette.Interactions[1].Request.Query)
+	require.Contains(t, string(cassette.Interactions[1].Request.Body), `"text":"hello"`)
+
+	server.Close()
+	replayer := NewReplayer(cassette)
+	api = newCassetteTestApi(t, server.URL, replayer)
+
+	// Requests match by query, not by order.
+	result, err := api.Messages.SendWithResult(ctx, NewMessage().SetChat(20).SetText("hello"))
+	require.NoError(t, err)
+	require.Equal(t, "mid.20", result.Body.Mid)
+	bot, err = api.Bots.GetBot(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), bot.UserId)
+	require.Equal(t, 1, replayer.Remaining())
+
+	// Each interaction is replayed once.
+	_, err = api.Bots.GetBot(ctx)
+	require.ErrorIs(t, err, ErrCassetteNoMatch)
+}
+
+func TestRecordedBody(t *testing.T) {
+	tests := []struct {
+		name string
+		body RecordedBody
+		want string
+	}{
+		{name: "text", body: RecordedBody(`{"a":1}`), want: `"{\"a\":1}"`},
+		{name: "binary", body: RecordedBody{0xff, 0xd8, 0xff}, want: `{"base64":"/9j/"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.body)
+			require.NoError(t, err)
+			require.JSONEq(t, tt.want, string(data))
+
+			var decoded RecordedBody
+			require.NoError(t, json.Unmarshal(data, &decoded))
+			require.Equal(t, tt.body, decoded)
+		})
+	}
+}
+
+func TestCassette_RedactsAccessToken(t *testing.T) {
+	var accessToken string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accessToken = r.URL.Query().Get(paramAccessToken)
+		json.NewEncoder(w).Encode(schemes.Error{NumberExist: []string{"79990000000"}})
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	m := NewMessage().SetReset(true)
+	m.message.BotToken = "other-bot-token"
+	m.message.PhoneNumbers = []string{"79990000000"}
+
+	recorder := NewRecorder(nil)
+	api := newCassetteTestApi(t, server.URL, recorder)
+	exists, _ := api.Messages.Check(ctx, m)
+	require.True(t, exists)
+	require.Equal(t, "other-bot-token", accessToken, "the token reaches the server")
+
+	fileName := filepath.Join(t.TempDir(), "cassette.json")
+	require.NoError(t, recorder.Cassette().Save(fileName))
+	data, err := os.ReadFile(fileName)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "other-bot-token")
+	require.Contains(t, string(data), paramAccessToken+"="+redacted)
+
+	cassette, err := LoadCassette(fileName)
+	require.NoError(t, err)
+	replayer := NewReplayer(cassette)
+	api = newCassetteTestApi(t, server.URL, replayer)
+	exists, _ = api.Messages.Check(ctx, m)
+	require.True(t, exists)
+	require.Zero(t, replayer.Remaining())
+}
@@ -2,9 +2,7 @@ package maxbot
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -38,11 +36,11 @@ func (a *chats) GetChats(ctx context.Context, count, marker int64) (*schemes.Cha
 	}
 	defer func() {
 		if err := body.Close(); err != nil {
-			log.Println(err)
+			a.client.logger.Error("failed to close response body", "error", err)
 		}
 	}()
 
-	return result, json.NewDecoder(body).Decode(result)
+	return result, a.client.decode(body, result)
 }
 
 // GetChat returns info about chat.
@@ -56,11 +54,11 @@ func (a *chats) GetChat(ctx context.Context, chatID int64) (*schemes.Chat, error
 	}
 	defer func() {
 		if err := body.Close(); err != nil {
-			log.Println(err)
+			a.client.logger.Error("failed to close response body", "error", err)
 		}
 	}()
 
-	return result, json.NewDecoder(body).Decode(result)
+	return result, a.client.decode(body, result)
 }
 
 // GetChatMembership returns chat membership info for the current bot.
@@ -74,11 +72,11 @@ func (a *chats) GetChatMembership(ctx context.Context, chatID int64) (*schemes.C
 	}
 	defer func() {
 		if err := body.Close(); err != nil {
-			log.Println(err)
+			a.client.logger.Error("failed to close response body", "error", err)
 		}
 	}()
 
-	return result, json.NewDecoder(body).Decode(result)
+	return result, a.client.decode(body, result)
 }
 
 // GetChatMembers returns users participated in chat.
@@ -98,11 +96,11 @@ func (a *chats) GetChatMembers(ctx context.Context, chatID, count, marker int64)
 	}
 	defer func() {
 		if err := body.Close(); err != nil {
-			log.Println(err)
+			a.client.logger.Error("failed to close response body", "error", err)
 		}
 	}()
 
-	return result, json.NewDecoder(body).Decode(result)
+	return result, a.client.decode(body, result)
 }
 
 func (a *chats) GetSpecificChatMembers(ctx context.Context, chatID int64, userIDs []int64) (*schemes.ChatMembersList, error) {
@@ -120,11 +118,11 @@ func (a *chats) GetSpecificChatMembers(ctx context.Context, chatID int64, userID
 	}
 	defer func() {
 		if err := body.Close(); err != nil {
-			log.Println(err)
+			a.client.logger.Error("failed to close response body", "error", err)
 		}
 	}()
 
-	return result, json.NewDecoder(body).Decode(result)
+	return result, a.client.decode(body, result)
 }
 
 func (a *chats) GetChatAdmins(ctx context.Context, chatID int64) (*schemes.ChatMembersList, error) {
@@ -136,11 +134,11 @@ func (a *chats) GetChatAdmins(ctx context.Context, chatID int64) (*schemes.ChatM
 	}
 	defer func() {
 		if err := body.Close(); err != nil {
-			log.Println(err)
+			a.client.logger.Error("failed to close response body", "error", err)
 		}
 	}()
 
-	return result, json.NewDecoder(body).Decode(result)
+	return result, a.client.decode(body, result)
 }
 
 // LeaveChat removes bot from chat members
@@ -154,11 +152,11 @@ func (a *chats) LeaveChat(ctx context.Context, chatID int64) (*schemes.SimpleQue
 	}
 	defer func() {
 		if err := body.Close(); err != nil {
-			log.Println(err)
+			a.client.logger.Error("failed to close response body", "error", err)
 		}
 	}()
 
-	return result, json.NewDecoder(body).Decode(result)
+	return result, a.client.decode(body, result)
 }
 
 // EditChat edits chat info: title, icon, etc…
@@ -172,11 +170,11 @@ func (a *chats) EditChat(ctx context.Context, chatID int64, update *schemes.Chat
 	}
 	defer func() {
 		if err := body.Close(); err != nil {
-			log.Println(err)
+			a.client.logger.Error("failed to close response body", "error", err)
 		}
 	}()
 
-	return result, json.NewDecoder(body).Decode(result)
+	return result, a.client.decode(body, result)
 }
 
 // AddMember adds members to the chat. Additional permissions may be required.
@@ -190,11 +188,11 @@ func (a *chats) AddMember(ctx context.Context, chatID int64, users schemes.UserI
 	}
 	defer func() {
 		if err := body.Close(); err != nil {
-			log.Println(err)
+			a.client.logger.Error("failed to close response body", "error", err)
 		}
 	}()
 
-	return result, json.NewDecoder(body).Decode(result)
+	return result, a.client.decode(body, result)
 }
 
 // RemoveMember removes a member from the chat. Additional permissions may be required.
@@ -209,11 +207,11 @@ func (a *chats) RemoveMember(ctx context.Context, chatID int64, userID int64) (*
 	}
 	defer func() {
 		if err := body.Close(); err != nil {
-			log.Println(err)
+			a.client.logger.Error("failed to close response body", "error", err)
 		}
 	}()
 
-	return result, json.NewDecoder(body).Decode(result)
+	return result, a.client.decode(body, result)
 }
 
 // SendAction send the bot action to the chat.
@@ -226,9 +224,9 @@ func (a *chats) SendAction(ctx context.Context, chatID int64, action schemes.Sen
 	}
 	defer func() {
 		if err := body.Close(); err != nil {
-			log.Println(err)
+			a.client.logger.Error("failed to close response body", "error", err)
 		}
 	}()
 
-	return result, json.NewDecoder(body).Decode(result)
+	return result, a.client.decode(body, result)
 }
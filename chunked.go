@@ -0,0 +1,214 @@
+package maxbot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/pavmos/max-bot-api-client-go/schemes"
+)
+
+// rangePattern matches the range acknowledged by the upload server: "bytes 0-1023/4096" or "0-1023/4096".
+var rangePattern = regexp.MustCompile(`^(?:bytes )?(\d+)-(\d+)/(\d+)$`)
+
+// UploadMediaFromFileChunked uploads the file to the Max server in chunks, see UploadMediaChunked.
+func (a *uploads) UploadMediaFromFileChunked(ctx context.Context, uploadType schemes.UploadType, filename string, opts ...UploadOption) (*schemes.UploadedInfo, error) {
+	fh, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+
+	info, err := fh.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	return a.UploadMediaChunked(ctx, uploadType, fh, info.Size(), filename, opts...)
+}
+
+// UploadMediaChunked uploads size bytes of the reader to the Max server in chunks with Content-Range.
+// If a chunk fails, it is resent from the last offset acknowledged by the server instead of restarting the upload.
+// The server state is not queried after a network failure: the chunk is resent from the last known offset,
+// and if the server has received a part of it, it answers 416 with the acknowledged range to continue from.
+// A successful response that does not advance the offset counts as a failed attempt, so a server that never
+// accepts the chunk fails the upload with ErrUploadStalled. If the server acknowledges the whole file without returning
+// the result, the token of the upload URL is returned, or ErrUploadIncomplete if there is none.
+// Chunk size and retries are set by WithChunkSize and WithChunkRetries.
+func (a *uploads) UploadMediaChunked(ctx context.Context, uploadType schemes.UploadType, reader io.ReaderAt, size int64, name string, opts ...UploadOption) (*schemes.UploadedInfo, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("invalid upload size %d", size)
+	}
+	if name == "" {
+		name = defaultUploadFileName
+	}
+
+	options := newUploadOptions(opts)
+	if !options.skipValidation {
+		head := make([]byte, min(size, sniffLength))
+		if _, err := reader.ReadAt(head, 0); err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		if err := a.validate(uploadType, head, name, size); err != nil {
+			return nil, err
+		}
+	}
+
+	endpoint, err := a.getUploadURL(ctx, uploadType)
+	if err != nil {
+		return nil, err
+	}
+
+	result := new(schemes.UploadedInfo)
+	tracker := newProgressTracker(size, options)
+
+	var offset int64
+	for attempt := 0; ; {
+		if offset >= size {
+			// The server has acknowledged the whole file, but its response with the result has been lost.
+			if endpoint.Token == "" {
+				return nil, ErrUploadIncomplete
+			}
+			tracker.update(size, true)
+
+			return &schemes.UploadedInfo{Token: endpoint.Token}, nil
+		}
+
+		start, end := offset, min(offset+options.chunkSize, size)
+		acked, done, err := a.uploadChunk(ctx, endpoint.Url, reader, start, end, size, name, result)
+		if acked >= 0 {
+			offset = acked
+		}
+		if err == nil && !done && acked >= 0 && acked <= start {
+			err = fmt.Errorf("%w: acknowledged offset %d", ErrUploadStalled, acked)
+		}
+
+		if err == nil {
+			attempt = 0
+			if done {
+				tracker.update(size, true)
+				if result.Token == "" {
+					result.Token = endpoint.Token
+				}
+
+				return result, nil
+			}
+			if acked < 0 {
+				offset = end
+			}
+			tracker.update(min(offset, size), false)
+
+			continue
+		}
+
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if !isRetryableUploadError(err) || attempt >= options.chunkRetries {
+			return nil, fmt.Errorf("chunked upload failed at offset %d: %w", offset, err)
+		}
+
+		retryWait := options.retryWait << attempt
+		attempt++
+		a.client.logger.Warn("chunk upload failed, resuming", "offset", offset, "wait", retryWait, "error", err)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(retryWait):
+		}
+	}
+}
+
+// uploadChunk sends bytes [start, end) of the file. It returns the offset acknowledged by the server or -1 if the server
+// has not reported it, and whether the upload is complete: the result is decoded then.
+func (a *uploads) uploadChunk(
+	ctx context.Context,
+	uploadURL string,
+	reader io.ReaderAt,
+	start, end, size int64,
+	name string,
+	result interface{},
+) (int64, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uploadURL, io.NewSectionReader(reader, start, end-start))
+	if err != nil {
+		return -1, false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.ContentLength = end - start
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end-1, size))
+	req.Header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filepath.Base(name)}))
+
+	resp, err := a.client.do(req, fmt.Sprintf("upload chunk %d-%d", start, end-1))
+	if err != nil {
+		return -1, false, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			a.client.logger.Error("failed to close response body", "error", err)
+		}
+	}()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	if err != nil {
+		return -1, false, &NetworkError{Op: "read upload response", Err: err}
+	}
+
+	acked := ackedOffset(data)
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return acked, false, uploadError(resp.StatusCode, bytes.NewReader(data))
+	}
+
+	if acked >= 0 || end < size {
+		return acked, false, nil
+	}
+
+	if err := json.Unmarshal(data, result); err != nil {
+		return -1, false, &SerializationError{Op: "unmarshal", Type: "upload result", Err: err}
+	}
+	a.client.checkUnknownFields(data, result)
+
+	return size, true, nil
+}
+
+// ackedOffset parses the range acknowledged by the upload server and returns the offset to continue from, or -1.
+func ackedOffset(data []byte) int64 {
+	m := rangePattern.FindSubmatch(bytes.TrimSpace(data))
+	if m == nil {
+		return -1
+	}
+
+	last, err := strconv.ParseInt(string(m[2]), 10, 64)
+	if err != nil {
+		return -1
+	}
+
+	return last + 1
+}
+
+func isRetryableUploadError(err error) bool {
+	if errors.Is(err, ErrUploadStalled) {
+		return true
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Code >= http.StatusInternalServerError ||
+			apiErr.Code == http.StatusTooManyRequests ||
+			apiErr.Code == http.StatusRequestedRangeNotSatisfiable
+	}
+
+	var netErr *NetworkError
+	var timeoutErr *TimeoutError
+
+	return errors.As(err, &netErr) || errors.As(err, &timeoutErr)
+}
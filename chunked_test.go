@@ -0,0 +1,200 @@
+package maxbot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavmos/max-bot-api-client-go/schemes"
+)
+
+// chunkServer is a local stand-in for a resumable upload endpoint. It accepts a chunk only if it continues the received data
+// and breaks the connection in the middle of the chunk number failAt, keeping the part read before the failure.
+// The chunk number dropAt is received in full, but the connection is broken before the response.
+// After the first chunk it answers the next stalls chunks with the unchanged range, dropping them; negative stalls never end.
+type chunkServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	received []byte
+	starts   []int64
+	requests int
+	failAt   int
+	dropAt   int
+	stalls   int
+}
+
+var contentRangePattern = regexp.MustCompile(`^bytes (\d+)-(\d+)/(\d+)$`)
+
+func newChunkServer(t *testing.T, failAt int) *chunkServer {
+	t.Helper()
+
+	s := &chunkServer{failAt: failAt}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/uploads", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(schemes.UploadEndpoint{Url: s.URL + "/upload", Token: "video-token"})
+	})
+	mux.HandleFunc("/upload", s.upload)
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *chunkServer) upload(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests++
+	m := contentRangePattern.FindStringSubmatch(r.Header.Get("Content-Range"))
+	if m == nil {
+		http.Error(w, "missing Content-Range", http.StatusBadRequest)
+		return
+	}
+	start, _ := strconv.ParseInt(m[1], 10, 64)
+	total, _ := strconv.ParseInt(m[3], 10, 64)
+	s.starts = append(s.starts, start)
+
+	if start != int64(len(s.received)) {
+		w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+		fmt.Fprintf(w, "0-%d/%d", len(s.received)-1, total)
+		return
+	}
+
+	if s.stalls != 0 && len(s.received) > 0 {
+		s.stalls--
+		fmt.Fprintf(w, "0-%d/%d", len(s.received)-1, total)
+		return
+	}
+
+	if s.requests == s.failAt {
+		half := make([]byte, r.ContentLength/2)
+		n, _ := io.ReadFull(r.Body, half)
+		s.received = append(s.received, half[:n]...)
+
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			conn.Close()
+		}
+		return
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.received = append(s.received, data...)
+
+	if s.requests == s.dropAt {
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			conn.Close()
+		}
+		return
+	}
+
+	if int64(len(s.received)) < total {
+		fmt.Fprintf(w, "0-%d/%d", len(s.received)-1, total)
+		return
+	}
+
+	json.NewEncoder(w).Encode(schemes.UploadedInfo{})
+}
+
+func TestUploadMediaChunked(t *testing.T) {
+	content := make([]byte, 10000)
+	for i := range content {
+		content[i] = byte(i % 251)
+	}
+
+	tests := []struct {
+		name       string
+		failAt     int
+		dropAt     int
+		wantStarts []int64
+	}{
+		{
+			name:       "without failures",
+			wantStarts: []int64{0, 4096, 8192},
+		},
+		{
+			// The chunk is resent from the last acknowledged offset, the server answers 416 with the range it has received.
+			name:       "resume after broken chunk",
+			failAt:     2,
+			wantStarts: []int64{0, 4096, 4096, 6144},
+		},
+		{
+			// The server has received the whole file, but the result is lost with the connection.
+			// The retry of the last chunk is answered 416 with the full range, and the token of the upload URL is used.
+			name:       "connection lost after last chunk",
+			dropAt:     3,
+			wantStarts: []int64{0, 4096, 8192, 8192},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newChunkServer(t, tt.failAt)
+			server.dropAt = tt.dropAt
+			api := newTestApi(t, server.URL)
+
+			var last UploadProgress
+			info, err := api.Uploads.UploadMediaChunked(context.Background(), schemes.VIDEO, bytes.NewReader(content), int64(len(content)), "video.mp4",
+				WithChunkSize(4096),
+				WithChunkRetries(3, time.Millisecond),
+				WithProgress(func(p UploadProgress) { last = p }),
+			)
+			require.NoError(t, err)
+			require.Equal(t, "video-token", info.Token)
+			require.Equal(t, content, server.received)
+			require.Equal(t, tt.wantStarts, server.starts)
+			require.True(t, last.Done)
+			require.Equal(t, int64(len(content)), last.Sent)
+		})
+	}
+}
+
+func TestUploadMediaChunked_Stalled(t *testing.T) {
+	content := make([]byte, 10000)
+
+	t.Run("retried", func(t *testing.T) {
+		server := newChunkServer(t, 0)
+		server.stalls = 2
+		api := newTestApi(t, server.URL)
+
+		_, err := api.Uploads.UploadMediaChunked(context.Background(), schemes.VIDEO, bytes.NewReader(content), int64(len(content)), "video.mp4",
+			WithChunkSize(4096),
+			WithChunkRetries(3, time.Millisecond),
+		)
+		require.NoError(t, err)
+		require.Equal(t, content, server.received)
+		require.Equal(t, []int64{0, 4096, 4096, 4096, 8192}, server.starts)
+	})
+
+	t.Run("never acknowledged", func(t *testing.T) {
+		server := newChunkServer(t, 0)
+		server.stalls = -1
+		api := newTestApi(t, server.URL)
+
+		start := time.Now()
+		_, err := api.Uploads.UploadMediaChunked(context.Background(), schemes.VIDEO, bytes.NewReader(content), int64(len(content)), "video.mp4",
+			WithChunkSize(4096),
+			WithChunkRetries(2, 10*time.Millisecond),
+		)
+		require.ErrorIs(t, err, ErrUploadStalled)
+		require.Equal(t, 4, server.requests, "the first chunk and three attempts of the second")
+		require.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond, "attempts wait between each other")
+	})
+}
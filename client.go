@@ -6,9 +6,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"sync/atomic"
+	"time"
 
 	"github.com/pavmos/max-bot-api-client-go/schemes"
 )
@@ -25,6 +27,14 @@ type client struct {
 	version    string
 	baseURL    *url.URL
 	httpClient *http.Client
+	logger     *slog.Logger
+	loggerSet  bool // SetLogger was called with a logger
+
+	unknownFields func(UnknownField)
+	metrics       Metrics
+
+	dryRun    atomic.Bool
+	dryRunSeq atomic.Int64
 }
 
 func newClient(key string, version string, baseURL *url.URL, httpClient *http.Client) *client {
@@ -39,6 +49,7 @@ func newClient(key string, version string, baseURL *url.URL, httpClient *http.Cl
 		version:    version,
 		baseURL:    baseURL,
 		httpClient: httpClient,
+		logger:     slog.New(slog.DiscardHandler),
 	}
 }
 
@@ -71,6 +82,10 @@ func (cl *client) requestReader(ctx context.Context, method, path string, query
 		query = url.Values{}
 	}
 
+	if cl.dryRun.Load() && isMutating(method, path) {
+		return cl.dryRunRequest(method, path, query, body)
+	}
+
 	u := *cl.baseURL
 	u.Path = path
 
@@ -90,27 +105,18 @@ func (cl *client) requestReader(ctx context.Context, method, path string, query
 		req.Header.Set("Content-Type", "application/json")
 	}
 
-	resp, err := cl.httpClient.Do(req)
+	start := time.Now()
+	resp, err := cl.do(req, fmt.Sprintf("%s %s", method, path))
 	if err != nil {
-		if urlErr, ok := err.(*url.Error); ok {
-			if urlErr.Timeout() {
-				return nil, cl.createTimeoutError(
-					fmt.Sprintf("%s %s", method, path),
-					fmt.Sprintf("request timeout exceeded (%v)", cl.httpClient.Timeout),
-				)
-			}
-		}
-
-		return nil, &NetworkError{
-			Op:  fmt.Sprintf("%s %s", method, path),
-			Err: err,
-		}
+		cl.observeRequest(method, path, 0, time.Since(start))
+		return nil, err
 	}
+	cl.observeRequest(method, path, resp.StatusCode, time.Since(start))
 
 	if resp.StatusCode != http.StatusOK {
 		defer func() {
 			if closeErr := resp.Body.Close(); closeErr != nil {
-				log.Println(closeErr)
+				cl.logger.Error("failed to close response body", "error", closeErr)
 			}
 		}()
 
@@ -129,6 +135,92 @@ func (cl *client) requestReader(ctx context.Context, method, path string, query
 	return resp.Body, nil
 }
 
+// do sends the request with the configured HTTP client.
+// Transport failures are returned as TimeoutError or NetworkError for the operation.
+// Every exchange is logged at the debug level with the token redacted.
+func (cl *client) do(req *http.Request, op string) (*http.Response, error) {
+	start := time.Now()
+	resp, err := cl.httpClient.Do(req)
+	cl.logExchange(req, resp, err, time.Since(start))
+	if err != nil {
+		if urlErr, ok := err.(*url.Error); ok {
+			if urlErr.Timeout() {
+				return nil, cl.createTimeoutError(
+					op,
+					fmt.Sprintf("request timeout exceeded (%v)", cl.httpClient.Timeout),
+				)
+			}
+		}
+
+		return nil, &NetworkError{
+			Op:  op,
+			Err: err,
+		}
+	}
+
+	return resp, nil
+}
+
+func (cl *client) logExchange(req *http.Request, resp *http.Response, err error, latency time.Duration) {
+	ctx := req.Context()
+	if !cl.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("host", req.URL.Host),
+		slog.String("path", req.URL.Path),
+		slog.Duration("latency", latency),
+	}
+	if token := req.Header.Get("Authorization"); token != "" {
+		attrs = append(attrs, slog.String("token", redactToken(token)))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+		cl.logger.LogAttrs(ctx, slog.LevelDebug, "request failed", attrs...)
+
+		return
+	}
+	attrs = append(attrs, slog.Int("status", resp.StatusCode))
+	cl.logger.LogAttrs(ctx, slog.LevelDebug, "request", attrs...)
+}
+
+// redactToken keeps only the beginning of the token, enough to tell bots apart in logs.
+func redactToken(token string) string {
+	const visible = 4
+	if len(token) <= 2*visible {
+		return redacted
+	}
+
+	return token[:visible] + "..." + redacted
+}
+
+// fetch downloads the remote file with the configured HTTP client.
+func (cl *client) fetch(ctx context.Context, fileURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	op := fmt.Sprintf("GET %s", req.URL.Redacted())
+	resp, err := cl.do(req, op)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+
+		return nil, &NetworkError{
+			Op:  op,
+			Err: fmt.Errorf("HTTP %d: %s", resp.StatusCode, http.StatusText(resp.StatusCode)),
+		}
+	}
+
+	return resp, nil
+}
+
 // Close closes the HTTP client.
 func (cl *client) Close() error {
 	if transport, ok := cl.httpClient.Transport.(*http.Transport); ok {
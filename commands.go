@@ -0,0 +1,318 @@
+package maxbot
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pavmos/max-bot-api-client-go/schemes"
+)
+
+const (
+	commandPrefix    = "/"
+	commandMention   = "@"
+	commandParamSign = ":"
+
+	tagArg         = "arg"
+	tagArgSkip     = "-"
+	tagArgRest     = "rest"
+	tagArgOptional = "optional"
+)
+
+// Command is a parsed bot command: /name[@bot][:param...] [args...]
+type Command struct {
+	Name    string   // Command name without the leading slash
+	Mention string   // Bot username the command is addressed to, if any
+	Params  []string // Colon separated params: /name:param1:param2
+	Args    []string // Space separated arguments, quotes group words into one argument
+	Raw     string   // Text after the command as is
+}
+
+// ParseCommand parses the text as a command. It returns ErrNotCommand if the text does not start with a slash.
+func ParseCommand(text string) (*Command, error) {
+	cmd, err := parseCommandHead(text)
+	if err != nil {
+		return nil, err
+	}
+
+	if cmd.Args, err = splitArgs(cmd.Raw); err != nil {
+		return nil, err
+	}
+
+	return cmd, nil
+}
+
+// parseCommandHead parses the command without splitting the arguments, so the command can be recognized
+// even if its arguments are malformed.
+func parseCommandHead(text string) (*Command, error) {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, commandPrefix) || len(text) == len(commandPrefix) {
+		return nil, ErrNotCommand
+	}
+
+	head, raw := text[len(commandPrefix):], ""
+	if i := strings.IndexFunc(head, unicode.IsSpace); i >= 0 {
+		head, raw = head[:i], strings.TrimLeftFunc(head[i:], unicode.IsSpace)
+	}
+
+	params := strings.Split(head, commandParamSign)
+	name, mention, _ := strings.Cut(params[0], commandMention)
+	if name == "" {
+		return nil, ErrNotCommand
+	}
+
+	return &Command{
+		Name:    name,
+		Mention: mention,
+		Params:  params[1:],
+		Raw:     raw,
+	}, nil
+}
+
+// Values returns params followed by args: the values Bind assigns to the struct fields.
+func (c *Command) Values() []string {
+	return append(append(make([]string, 0, len(c.Params)+len(c.Args)), c.Params...), c.Args...)
+}
+
+// Bind assigns command values to the fields of the struct pointed by v in declaration order.
+// Field tag `arg:"-"` skips the field, `arg:"optional"` allows the value to be missing
+// and `arg:"rest"` assigns the rest of the command text as typed, starting from the first remaining argument.
+// Supported field types are strings, booleans, integers, floats and time.Duration.
+func (c *Command) Bind(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w: expected pointer to struct, got %T", ErrCommandArgs, v)
+	}
+	rv = rv.Elem()
+	rt := rv.Type()
+
+	values := c.Values()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		tag := field.Tag.Get(tagArg)
+		if !field.IsExported() || tag == tagArgSkip {
+			continue
+		}
+
+		if tag == tagArgRest {
+			if field.Type.Kind() != reflect.String {
+				return fmt.Errorf("%w: field %s must be a string to hold the rest", ErrCommandArgs, strings.ToLower(field.Name))
+			}
+			rv.Field(i).SetString(c.rest(len(c.Values()) - len(values)))
+			values = nil
+			continue
+		}
+
+		if len(values) == 0 {
+			if tag == tagArgOptional {
+				continue
+			}
+			return fmt.Errorf("%w: missing value for %s", ErrCommandArgs, strings.ToLower(field.Name))
+		}
+
+		if err := setArg(rv.Field(i), values[0]); err != nil {
+			return fmt.Errorf("%w: invalid value %q for %s: %v", ErrCommandArgs, values[0], strings.ToLower(field.Name), err)
+		}
+		values = values[1:]
+	}
+
+	return nil
+}
+
+// rest returns the values starting from the value with the given index: the remaining params joined by space
+// followed by the raw text of the remaining arguments.
+func (c *Command) rest(from int) string {
+	parts := make([]string, 0, len(c.Params)+1)
+	if from < len(c.Params) {
+		parts = append(parts, c.Params[from:]...)
+	}
+
+	arg := max(from-len(c.Params), 0)
+	if arg < len(c.Args) {
+		args, starts, err := scanArgs(c.Raw)
+		if err == nil && slices.Equal(args, c.Args) {
+			parts = append(parts, c.Raw[starts[arg]:])
+		} else {
+			// The command is not parsed from the text, there is no raw text to take the rest from.
+			parts = append(parts, c.Args[arg:]...)
+		}
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// CommandParser parses commands addressed to the bot and checks them against the bot command list.
+type CommandParser struct {
+	username string
+	commands map[string]schemes.BotCommand
+}
+
+// NewCommandParser returns a parser. Commands mentioning another username are rejected.
+// If the command list is not empty, unknown commands are rejected as well.
+func NewCommandParser(username string, commands []schemes.BotCommand) *CommandParser {
+	p := &CommandParser{
+		username: username,
+		commands: make(map[string]schemes.BotCommand, len(commands)),
+	}
+	for _, c := range commands {
+		p.commands[strings.ToLower(strings.TrimPrefix(c.Name, commandPrefix))] = c
+	}
+
+	return p
+}
+
+// NewCommandParser returns a parser for the current bot using its username and registered commands.
+func (a *Api) NewCommandParser(ctx context.Context) (*CommandParser, error) {
+	info, err := a.Bots.GetBot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCommandParser(info.Username, info.Commands), nil
+}
+
+// Parse parses the command from the text.
+func (p *CommandParser) Parse(text string) (*Command, error) {
+	cmd, err := parseCommandHead(text)
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.addressed(cmd) {
+		return nil, fmt.Errorf("%w: @%s", ErrCommandForeign, cmd.Mention)
+	}
+
+	if len(p.commands) > 0 {
+		if _, ok := p.commands[strings.ToLower(cmd.Name)]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrCommandUnknown, cmd.Name)
+		}
+	}
+
+	if cmd.Args, err = splitArgs(cmd.Raw); err != nil {
+		return nil, err
+	}
+
+	return cmd, nil
+}
+
+// addressed reports whether the command mentions no bot or the bot of the parser.
+func (p *CommandParser) addressed(cmd *Command) bool {
+	return cmd.Mention == "" || p.username == "" || strings.EqualFold(cmd.Mention, p.username)
+}
+
+// ParseUpdate parses the command from the text of the created message.
+func (p *CommandParser) ParseUpdate(upd *schemes.MessageCreatedUpdate) (*Command, error) {
+	return p.Parse(upd.GetText())
+}
+
+// splitArgs splits the text by spaces. Single or double quotes opening an argument group words up to the closing quote,
+// quotes inside a word are kept as is. Backslash escapes the next character.
+func splitArgs(text string) ([]string, error) {
+	args, _, err := scanArgs(text)
+
+	return args, err
+}
+
+// scanArgs splits the text like splitArgs and also returns the byte offset of every argument in the text.
+func scanArgs(text string) ([]string, []int, error) {
+	var args []string
+	var starts []int
+	var current strings.Builder
+	var quote rune
+	inArg, escaped := false, false
+
+	begin := func(i int) {
+		if !inArg {
+			starts = append(starts, i)
+			inArg = true
+		}
+	}
+
+	for i, r := range text {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			begin(i)
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case (r == '"' || r == '\'') && !inArg:
+			begin(i)
+			quote = r
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			begin(i)
+			current.WriteRune(r)
+		}
+	}
+
+	if quote != 0 || escaped {
+		return nil, nil, fmt.Errorf("%w: unterminated quote or escape", ErrCommandSyntax)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+
+	return args, starts, nil
+}
+
+func setArg(field reflect.Value, value string) error {
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+
+	return nil
+}
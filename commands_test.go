@@ -0,0 +1,128 @@
+package maxbot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavmos/max-bot-api-client-go/schemes"
+)
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		text string
+		want *Command
+		err  error
+	}{
+		{
+			text: "/start",
+			want: &Command{Name: "start", Params: []string{}},
+		},
+		{
+			text: "/ban @user 1h",
+			want: &Command{Name: "ban", Params: []string{}, Args: []string{"@user", "1h"}, Raw: "@user 1h"},
+		},
+		{
+			text: "/cmd@mybot args",
+			want: &Command{Name: "cmd", Mention: "mybot", Params: []string{}, Args: []string{"args"}, Raw: "args"},
+		},
+		{
+			text: "/command:paramA:paramB",
+			want: &Command{Name: "command", Params: []string{"paramA", "paramB"}},
+		},
+		{
+			text: `/note "buy milk" 'and bread' a\ b`,
+			want: &Command{Name: "note", Params: []string{}, Args: []string{"buy milk", "and bread", "a b"}, Raw: `"buy milk" 'and bread' a\ b`},
+		},
+		{
+			text: "/note it's raining",
+			want: &Command{Name: "note", Params: []string{}, Args: []string{"it's", "raining"}, Raw: "it's raining"},
+		},
+		{
+			text: "/say don't",
+			want: &Command{Name: "say", Params: []string{}, Args: []string{"don't"}, Raw: "don't"},
+		},
+		{
+			text: `/say a"b c"d`,
+			want: &Command{Name: "say", Params: []string{}, Args: []string{`a"b`, `c"d`}, Raw: `a"b c"d`},
+		},
+		{
+			text: `/note "unterminated`,
+			err:  ErrCommandSyntax,
+		},
+		{
+			text: "any text",
+			err:  ErrNotCommand,
+		},
+		{
+			text: "/",
+			err:  ErrNotCommand,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := ParseCommand(tt.text)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCommandBind(t *testing.T) {
+	type banArgs struct {
+		User     string
+		Duration time.Duration `arg:"optional"`
+		internal int
+	}
+
+	type noteArgs struct {
+		Priority int
+		Text     string `arg:"rest"`
+	}
+
+	cmd, err := ParseCommand("/ban @user 1h")
+	require.NoError(t, err)
+
+	ban := banArgs{}
+	require.NoError(t, cmd.Bind(&ban))
+	require.Equal(t, banArgs{User: "@user", Duration: time.Hour}, ban)
+
+	cmd, err = ParseCommand("/note:2 buy milk")
+	require.NoError(t, err)
+
+	note := noteArgs{}
+	require.NoError(t, cmd.Bind(&note))
+	require.Equal(t, noteArgs{Priority: 2, Text: "buy milk"}, note)
+
+	cmd, err = ParseCommand(`/note 3 don't  forget "the milk"`)
+	require.NoError(t, err)
+	require.NoError(t, cmd.Bind(&note))
+	require.Equal(t, noteArgs{Priority: 3, Text: `don't  forget "the milk"`}, note, "the rest is the text as typed")
+
+	cmd, err = ParseCommand("/note high")
+	require.NoError(t, err)
+	require.ErrorIs(t, cmd.Bind(&note), ErrCommandArgs)
+}
+
+func TestCommandParser(t *testing.T) {
+	parser := NewCommandParser("mybot", []schemes.BotCommand{{Name: "ban"}, {Name: "/help"}})
+
+	_, err := parser.Parse("/ban@MyBot @user")
+	require.NoError(t, err)
+
+	_, err = parser.Parse("/help")
+	require.NoError(t, err)
+
+	_, err = parser.Parse("/ban@otherbot @user")
+	require.ErrorIs(t, err, ErrCommandForeign)
+
+	_, err = parser.Parse("/kick @user")
+	require.ErrorIs(t, err, ErrCommandUnknown)
+}
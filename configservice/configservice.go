@@ -1,7 +1,7 @@
 package configservice
 
 import (
-	"github.com/rs/zerolog/log"
+	"log/slog"
 )
 
 //go:generate mockgen -source=configservice.go -destination=./mocks/configservice_mock.go -package=mocks
@@ -17,14 +17,24 @@ type ConfigInterface interface {
 }
 
 func NewConfigInterface(configPath string) ConfigInterface {
-	cs := Config{}
+	return NewConfigInterfaceWithLogger(configPath, nil)
+}
+
+// NewConfigInterfaceWithLogger reads the configuration reporting problems to the logger, slog.Default() if it is nil.
+// Nil is returned if the configuration can't be read, the reason is only in the log.
+func NewConfigInterfaceWithLogger(configPath string, logger *slog.Logger) ConfigInterface {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	cs := Config{logger: logger}
 	if err := cs.readCompositeYamlConfigFile(configPath); err != nil {
-		log.Err(err).Msg("NewConfigService loadConfigFromYaml")
+		logger.Error("NewConfigService loadConfigFromYaml", "error", err)
 		return nil
 	}
 
 	if err := cs.loadConfigFromEnv(); err != nil {
-		log.Err(err).Msg("NewConfigService loadConfigFromEnv")
+		logger.Error("NewConfigService loadConfigFromEnv", "error", err)
 		return nil
 	}
 	return &cs
@@ -3,13 +3,13 @@ package configservice
 import (
 	"encoding/json"
 	"io"
+	"log/slog"
 	"os"
 	"path"
 	"regexp"
 	"strings"
 
 	"github.com/caarlos0/env/v6"
-	"github.com/rs/zerolog/log"
 	"gopkg.in/yaml.v2"
 )
 
@@ -26,15 +26,23 @@ type YamlConfig struct {
 
 type Config struct {
 	config YamlConfig
+	logger *slog.Logger
+}
+
+func (c *Config) log() *slog.Logger {
+	if c.logger == nil {
+		return slog.Default()
+	}
+	return c.logger
 }
 
 func (c *Config) SetEnvVariables(str string) string {
-	re, err := regexp.Compile(`(\$\((\w+)\))`)
+	re := regexp.MustCompile(`(\$\((\w+)\))`)
 	res := re.FindAllStringSubmatch(str, -1)
 	for _, v := range res {
 		if len(v) == 3 {
 			if os.Getenv(v[2]) == "" {
-				log.Err(err).Msg("variable " + v[1] + " is not defined!")
+				c.log().Error("variable is not defined", "variable", v[1])
 			}
 			str = strings.Replace(str, v[1], os.Getenv(v[2]), -1)
 		}
@@ -91,13 +99,13 @@ func (c *Config) loadConfigFromEnv() error {
 func (c *Config) readYamlConfigFile(path string) error {
 	filename, err := os.Open(path)
 	if err != nil {
-		log.Err(err).Msg("readYamlConfigFile os.Open")
+		c.log().Error("readYamlConfigFile os.Open", "error", err)
 		return err
 	}
 	defer func() {
 		err = filename.Close()
 		if err != nil {
-			log.Err(err).Msg("filename.Close()")
+			c.log().Error("filename.Close()", "error", err)
 		}
 	}()
 
@@ -106,7 +114,7 @@ func (c *Config) readYamlConfigFile(path string) error {
 
 	err = yaml.Unmarshal(unsource, &c.config)
 	if err != nil {
-		log.Err(err).Msg("readYamlConfigFile yaml.Unmarshal")
+		c.log().Error("readYamlConfigFile yaml.Unmarshal", "error", err)
 	}
 
 	return err
@@ -130,7 +138,7 @@ func (c *Config) readCompositeYamlConfigFile(configPath string) error {
 		composed += ext
 
 		if err := c.readYamlConfigFile(composed); err != nil {
-			log.Err(err).Msg("ReadCompositeYamlConfigFile")
+			c.log().Error("ReadCompositeYamlConfigFile", "error", err)
 			return err
 		}
 	}
@@ -11,6 +11,12 @@ const (
 	maxUpdatesLimit = 50
 
 	maxRetries = 3
+
+	defaultAttachmentRetries = 10
+	defaultAttachmentWait    = 500 * time.Millisecond
+	maxAttachmentWait        = 8 * time.Second
+
+	errCodeAttachmentNotReady = "attachment.not.ready"
 )
 
 const (
@@ -26,12 +32,14 @@ const (
 	pathUpload        = "uploads"
 	pathMessages      = "messages"
 	pathSubscriptions = "subscriptions"
+	pathVideos        = "videos"
 
 	formatPathChatsID           = "chats/%d"
 	formatPathChatsActions      = "chats/%d/actions"
 	formatPathChatsMembers      = "chats/%d/members"
 	formatPathChatsMembersMe    = "chats/%d/members/me"
 	formatPathChatsMembersAdmin = "chats/%d/members/admins"
+	formatPathVideos            = "videos/%s"
 )
 
 const (
@@ -2,8 +2,6 @@ package maxbot
 
 import (
 	"context"
-	"encoding/json"
-	"log/slog"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -46,11 +44,11 @@ func (a *debugs) sendMessage(ctx context.Context, reset bool, chatID int64, user
 	}
 	defer func() {
 		if err := body.Close(); err != nil {
-			slog.Error("failed to close response body", "error", err)
+			a.client.logger.Error("failed to close response body", "error", err)
 		}
 	}()
 
-	if err = json.NewDecoder(body).Decode(result); err != nil {
+	if err = a.client.decode(body, result); err != nil {
 		return nil
 	}
 	if result.Code == "" {
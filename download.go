@@ -0,0 +1,216 @@
+package maxbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pavmos/max-bot-api-client-go/schemes"
+)
+
+// DownloadInfo describes the downloaded attachment.
+type DownloadInfo struct {
+	Name        string // File name derived from the attachment or the response
+	ContentType string // Content type reported by the server
+	Size        int64  // Number of bytes written
+}
+
+// downloadSource is what is needed to download an attachment.
+type downloadSource struct {
+	url          string
+	name         string
+	size         int64
+	contentTypes []string
+}
+
+// Download writes the content of the received photo, video, audio or file attachment to w using the configured HTTP client.
+// The size is limited by WithMaxSize and the content type must match the kind of the attachment unless set by WithContentTypes.
+func (a *Api) Download(ctx context.Context, attachment any, w io.Writer, opts ...DownloadOption) (*DownloadInfo, error) {
+	options := newDownloadOptions(opts)
+	source, err := attachmentSource(attachment)
+	if err != nil {
+		return nil, err
+	}
+	if options.contentTypes != nil {
+		source.contentTypes = options.contentTypes
+	}
+
+	if options.maxSize > 0 && source.size > options.maxSize {
+		return nil, fmt.Errorf("%w: %d bytes, limit %d", ErrDownloadTooLarge, source.size, options.maxSize)
+	}
+
+	resp, err := a.client.fetch(ctx, source.url)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			a.client.logger.Error("failed to close response body", "error", err)
+		}
+	}()
+
+	if options.maxSize > 0 && resp.ContentLength > options.maxSize {
+		return nil, fmt.Errorf("%w: %d bytes, limit %d", ErrDownloadTooLarge, resp.ContentLength, options.maxSize)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if !matchContentType(contentType, source.contentTypes) {
+		return nil, fmt.Errorf("%w: %q", ErrDownloadContentType, contentType)
+	}
+
+	info := &DownloadInfo{
+		Name:        downloadName(source, attachmentName(resp), contentType),
+		ContentType: contentType,
+	}
+
+	body := io.Reader(resp.Body)
+	if options.maxSize > 0 {
+		body = io.LimitReader(resp.Body, options.maxSize+1)
+	}
+	info.Size, err = io.Copy(w, body)
+	if err != nil {
+		return info, &NetworkError{Op: "download attachment", Err: err}
+	}
+	if options.maxSize > 0 && info.Size > options.maxSize {
+		return info, fmt.Errorf("%w: limit %d", ErrDownloadTooLarge, options.maxSize)
+	}
+
+	return info, nil
+}
+
+// DownloadToFile downloads the attachment into the directory, naming the file after the attachment, and returns its path.
+// The file appears only when the download has succeeded. Existing files are kept: if the name is taken,
+// a number is added to it, e.g. "file (1).png".
+func (a *Api) DownloadToFile(ctx context.Context, attachment any, dir string, opts ...DownloadOption) (string, error) {
+	tmp, err := os.CreateTemp(dir, ".download-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	info, err := a.Download(ctx, attachment, tmp, opts...)
+	if err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+
+	fileName, err := reserveFileName(dir, info.Name)
+	if err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), fileName); err != nil {
+		os.Remove(fileName)
+		return "", err
+	}
+
+	return fileName, nil
+}
+
+// reserveFileName creates an empty file with the name or, if it is taken, with the first free numbered name,
+// so concurrent downloads never replace each other.
+func reserveFileName(dir, name string) (string, error) {
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+
+	for i := 0; ; i++ {
+		candidate := name
+		if i > 0 {
+			candidate = fmt.Sprintf("%s (%d)%s", base, i, ext)
+		}
+
+		fileName := filepath.Join(dir, candidate)
+		fh, err := os.OpenFile(fileName, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+
+		return fileName, fh.Close()
+	}
+}
+
+// attachmentSource returns the URL and the expected content of the received attachment.
+func attachmentSource(attachment any) (downloadSource, error) {
+	switch a := attachment.(type) {
+	case *schemes.PhotoAttachment:
+		return downloadSource{url: a.Payload.Url, contentTypes: []string{"image/"}}, nil
+	case *schemes.VideoAttachment:
+		return downloadSource{url: a.Payload.Url, contentTypes: []string{"video/"}}, nil
+	case *schemes.AudioAttachment:
+		return downloadSource{url: a.Payload.Url, contentTypes: []string{"audio/"}}, nil
+	case *schemes.FileAttachment:
+		return downloadSource{url: a.Payload.Url, name: a.Filename, size: a.Size}, nil
+	}
+
+	return downloadSource{}, fmt.Errorf("%w: %T", ErrDownloadUnsupported, attachment)
+}
+
+// matchContentType reports whether the content type is accepted. Generic binary or missing types are accepted,
+// as the storage does not always know the type of the content.
+func matchContentType(contentType string, accepted []string) bool {
+	if len(accepted) == 0 {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType == "application/octet-stream" {
+		return true
+	}
+
+	for _, t := range accepted {
+		if mediaType == t || strings.HasSuffix(t, "/") && strings.HasPrefix(mediaType, t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// downloadName returns a safe file name for the attachment: the name of the file attachment, the name from
+// Content-Disposition or the last element of the URL path if it has an extension, and the default name
+// with the extension of the content type otherwise.
+func downloadName(source downloadSource, disposition string, contentType string) string {
+	for _, name := range []string{source.name, disposition} {
+		if name = baseName(name); name != "" {
+			return name
+		}
+	}
+
+	if u, err := url.Parse(source.url); err == nil {
+		if name := baseName(u.Path); path.Ext(name) != "" {
+			return name
+		}
+	}
+
+	name := defaultUploadFileName
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		if exts, err := mime.ExtensionsByType(mediaType); err == nil && len(exts) > 0 {
+			name += exts[0]
+		}
+	}
+
+	return name
+}
+
+// baseName strips the directories from the name, so it cannot point outside the download directory.
+func baseName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	if name == "." || name == "/" || name == ".." {
+		return ""
+	}
+
+	return name
+}
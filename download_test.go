@@ -0,0 +1,121 @@
+package maxbot
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavmos/max-bot-api-client-go/schemes"
+)
+
+func TestDownload(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("/photo", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("png"))
+	})
+	mux.HandleFunc("/named", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "video/mp4")
+		w.Header().Set("Content-Disposition", `attachment; filename="../clip.mp4"`)
+		w.Write([]byte("mp4"))
+	})
+	mux.HandleFunc("/doc.pdf", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write(bytes.Repeat([]byte("x"), 100))
+	})
+	mux.HandleFunc("/chunked", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.(http.Flusher).Flush()
+		w.Write(bytes.Repeat([]byte("x"), 100))
+	})
+
+	api, err := New("test")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	photo := &schemes.PhotoAttachment{Payload: schemes.PhotoAttachmentPayload{Url: server.URL + "/photo"}}
+	video := &schemes.VideoAttachment{Payload: schemes.MediaAttachmentPayload{Url: server.URL + "/named"}}
+	file := &schemes.FileAttachment{Payload: schemes.FileAttachmentPayload{Url: server.URL + "/doc.pdf"}, Filename: "report.pdf", Size: 100}
+
+	t.Run("writer", func(t *testing.T) {
+		var buf bytes.Buffer
+		info, err := api.Download(ctx, photo, &buf)
+		require.NoError(t, err)
+		require.Equal(t, "png", buf.String())
+		require.Equal(t, &DownloadInfo{Name: "file.png", ContentType: "image/png", Size: 3}, info)
+	})
+
+	t.Run("file names", func(t *testing.T) {
+		dir := t.TempDir()
+
+		fileName, err := api.DownloadToFile(ctx, video, dir)
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(dir, "clip.mp4"), fileName)
+
+		fileName, err = api.DownloadToFile(ctx, file, dir)
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(dir, "report.pdf"), fileName)
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Len(t, entries, 2, "temporary files must be removed")
+	})
+
+	t.Run("existing files are kept", func(t *testing.T) {
+		dir := t.TempDir()
+
+		var fileNames []string
+		for range 3 {
+			fileName, err := api.DownloadToFile(ctx, photo, dir)
+			require.NoError(t, err)
+			fileNames = append(fileNames, filepath.Base(fileName))
+		}
+		require.Equal(t, []string{"file.png", "file (1).png", "file (2).png"}, fileNames)
+
+		for _, name := range fileNames {
+			data, err := os.ReadFile(filepath.Join(dir, name))
+			require.NoError(t, err)
+			require.Equal(t, "png", string(data))
+		}
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Len(t, entries, 3)
+	})
+
+	t.Run("content type", func(t *testing.T) {
+		audio := &schemes.AudioAttachment{Payload: schemes.MediaAttachmentPayload{Url: server.URL + "/photo"}}
+		_, err := api.Download(ctx, audio, &bytes.Buffer{})
+		require.ErrorIs(t, err, ErrDownloadContentType)
+
+		_, err = api.Download(ctx, audio, &bytes.Buffer{}, WithContentTypes("audio/", "image/png"))
+		require.NoError(t, err)
+	})
+
+	t.Run("size limit", func(t *testing.T) {
+		_, err := api.Download(ctx, file, &bytes.Buffer{}, WithMaxSize(10))
+		require.ErrorIs(t, err, ErrDownloadTooLarge)
+
+		unsized := &schemes.FileAttachment{Payload: schemes.FileAttachmentPayload{Url: server.URL + "/chunked"}}
+		dir := t.TempDir()
+		_, err = api.DownloadToFile(ctx, unsized, dir, WithMaxSize(10))
+		require.ErrorIs(t, err, ErrDownloadTooLarge)
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Empty(t, entries, "failed download must not leave files")
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		_, err := api.Download(ctx, &schemes.LocationAttachment{}, &bytes.Buffer{})
+		require.ErrorIs(t, err, ErrDownloadUnsupported)
+	})
+}
@@ -0,0 +1,126 @@
+package maxbot
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pavmos/max-bot-api-client-go/schemes"
+)
+
+const dryRunMidPrefix = "mid.dry-run."
+
+// SetDryRun enables the dry-run mode: mutating requests (sending, editing and deleting messages, answers,
+// changes of chats, members, the bot and subscriptions) are logged with the full request body instead of being sent,
+// and return synthetic successful results. Read requests and uploads of files still reach the API.
+// The requests are logged to the logger set by SetLogger or, if there is none, to slog.Default().
+func (a *Api) SetDryRun(enabled bool) {
+	a.client.dryRun.Store(enabled)
+}
+
+// isMutating reports whether the request changes the state of the platform.
+func isMutating(method, path string) bool {
+	return method != http.MethodGet && path != pathUpload
+}
+
+// dryRunRequest logs the mutating request and returns a synthetic response for it.
+func (cl *client) dryRunRequest(method, path string, query url.Values, body io.Reader) (io.ReadCloser, error) {
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = io.ReadAll(body); err != nil {
+			return nil, err
+		}
+	}
+
+	target := path
+	if len(query) > 0 {
+		target += "?" + redactQuery(query).Encode()
+	}
+	cl.dryRunLogger().Info("dry run", "method", method, "target", target, "body", string(data))
+
+	result, err := json.Marshal(cl.dryRunResult(method, path, query, data))
+	if err != nil {
+		return nil, &SerializationError{Op: "marshal", Type: "dry run result", Err: err}
+	}
+
+	return io.NopCloser(bytes.NewReader(result)), nil
+}
+
+// dryRunLogger returns the logger for skipped requests. Unlike the other logs they are the only result of the dry run,
+// so they are not discarded when no logger is set.
+func (cl *client) dryRunLogger() *slog.Logger {
+	if cl.loggerSet {
+		return cl.logger
+	}
+
+	return slog.Default()
+}
+
+// dryRunResult returns the result the API would return for the successful request.
+func (cl *client) dryRunResult(method, path string, query url.Values, data []byte) any {
+	switch {
+	case method == http.MethodPost && path == pathMessages:
+		body := new(schemes.NewMessageBody)
+		_ = json.Unmarshal(data, body)
+
+		chatID, _ := strconv.ParseInt(query.Get(paramChatID), 10, 64)
+		userID, _ := strconv.ParseInt(query.Get(paramUserID), 10, 64)
+		rawAttachments := make([]json.RawMessage, 0, len(body.Attachments))
+		for _, attachment := range body.Attachments {
+			if raw, err := json.Marshal(attachment); err == nil {
+				rawAttachments = append(rawAttachments, raw)
+			}
+		}
+
+		return schemes.SendMessageResult{Message: schemes.Message{
+			Recipient: schemes.Recipient{ChatId: chatID, UserId: userID},
+			Timestamp: time.Now().UnixMilli(),
+			Body: schemes.MessageBody{
+				Mid:            dryRunMidPrefix + strconv.FormatInt(cl.dryRunSeq.Add(1), 10),
+				Text:           body.Text,
+				RawAttachments: rawAttachments,
+				Markups:        body.Markups,
+			},
+		}}
+	case method == http.MethodPatch && path == pathMe:
+		info := new(schemes.BotInfo)
+		_ = json.Unmarshal(data, info)
+
+		return info
+	case method == http.MethodPatch && strings.HasPrefix(path, pathChats+"/") && strings.Count(path, "/") == 1:
+		chatID, _ := strconv.ParseInt(strings.TrimPrefix(path, pathChats+"/"), 10, 64)
+		patch := new(schemes.ChatPatch)
+		_ = json.Unmarshal(data, patch)
+
+		chat := &schemes.Chat{ChatId: chatID, Status: schemes.ACTIVE, Title: patch.Title}
+		if patch.Icon != nil && patch.Icon.Url != "" {
+			chat.Icon = &schemes.Image{Url: patch.Icon.Url}
+		}
+
+		return chat
+	}
+
+	return schemes.SimpleQueryResult{Success: true}
+}
+
+// redactQuery hides the access token of requests on behalf of another bot.
+func redactQuery(query url.Values) url.Values {
+	if query.Get(paramAccessToken) == "" {
+		return query
+	}
+
+	redactedQuery := url.Values{}
+	for key, values := range query {
+		redactedQuery[key] = values
+	}
+	redactedQuery.Set(paramAccessToken, redacted)
+
+	return redactedQuery
+}
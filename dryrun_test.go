@@ -0,0 +1,88 @@
+package maxbot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavmos/max-bot-api-client-go/schemes"
+)
+
+func TestDryRun(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		json.NewEncoder(w).Encode(schemes.Chat{ChatId: 1, Title: "team"})
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	api := newTestApi(t, server.URL)
+	api.SetLogger(slog.New(slog.NewTextHandler(&logs, nil)))
+	api.SetDryRun(true)
+	ctx := context.Background()
+
+	chat, err := api.Chats.GetChat(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, "team", chat.Title)
+
+	keyboard := NewKeyboardBuilder()
+	keyboard.AddRow().AddCallback("Yes", schemes.POSITIVE, "yes")
+	msg, err := api.Messages.SendWithResult(ctx, NewMessage().SetChat(1).SetText("broadcast").AddKeyboard(keyboard))
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(msg.Body.Mid, dryRunMidPrefix))
+	require.Equal(t, int64(1), msg.Recipient.ChatId)
+	require.Equal(t, "broadcast", msg.Body.Text)
+	require.Len(t, msg.Body.RawAttachments, 1)
+
+	require.NoError(t, api.Messages.EditMessage(ctx, msg.Body.Mid, NewMessage().SetText("edited")))
+	deleted, err := api.Messages.DeleteMessage(ctx, msg.Body.Mid)
+	require.NoError(t, err)
+	require.True(t, deleted.Success)
+
+	edited, err := api.Chats.EditChat(ctx, 1, &schemes.ChatPatch{Title: "renamed"})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), edited.ChatId)
+	require.Equal(t, "renamed", edited.Title)
+
+	removed, err := api.Chats.RemoveMember(ctx, 1, 2)
+	require.NoError(t, err)
+	require.True(t, removed.Success)
+
+	require.Equal(t, []string{"GET /chats/1"}, requests)
+	require.Contains(t, logs.String(), `msg="dry run" method=POST target="messages?chat_id=1" body="{\"text\":\"broadcast\"`)
+	require.Contains(t, logs.String(), `msg="dry run" method=PATCH target=chats/1 body="{\"title\":\"renamed\"}"`)
+	require.Contains(t, logs.String(), `msg="dry run" method=DELETE target="chats/1/members?user_id=2" body=""`)
+
+	api.SetDryRun(false)
+	_, err = api.Messages.DeleteMessage(ctx, msg.Body.Mid)
+	require.NoError(t, err)
+	require.Equal(t, []string{"GET /chats/1", "DELETE /messages"}, requests)
+}
+
+func TestDryRun_DefaultLogger(t *testing.T) {
+	var logs bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+
+	api := newTestApi(t, "http://127.0.0.1:0")
+	api.SetDryRun(true)
+	require.NoError(t, api.Messages.Send(context.Background(), NewMessage().SetChat(1).SetText("broadcast")))
+	require.Contains(t, logs.String(), `msg="dry run" method=POST target="messages?chat_id=1"`)
+
+	// A logger set by SetLogger replaces the default one.
+	logs.Reset()
+	var own bytes.Buffer
+	api.SetLogger(slog.New(slog.NewTextHandler(&own, nil)))
+	require.NoError(t, api.Messages.Send(context.Background(), NewMessage().SetChat(1).SetText("broadcast")))
+	require.Empty(t, logs.String())
+	require.Contains(t, own.String(), `msg="dry run"`)
+}
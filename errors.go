@@ -8,6 +8,33 @@ import (
 var (
 	ErrEmptyToken = errors.New("bot token is empty")
 	ErrInvalidURL = errors.New("invalid API URL")
+
+	ErrCallbackNoMessage = errors.New("callback has no original message to edit")
+	ErrCallbackAnswered  = errors.New("callback has already been answered")
+
+	ErrAttachmentNotResendable = errors.New("attachment cannot be sent again")
+
+	ErrPayloadTooLong   = errors.New("callback payload is too long")
+	ErrPayloadMalformed = errors.New("callback payload is malformed")
+	ErrPayloadSignature = errors.New("callback payload signature mismatch")
+	ErrPayloadVersion   = errors.New("callback payload version is not supported")
+
+	ErrNotCommand     = errors.New("text is not a command")
+	ErrCommandSyntax  = errors.New("invalid command syntax")
+	ErrCommandUnknown = errors.New("unknown command")
+	ErrCommandForeign = errors.New("command is addressed to another bot")
+	ErrCommandArgs    = errors.New("invalid command arguments")
+
+	ErrUploadIncomplete = errors.New("upload server has not returned the result after the last chunk")
+	ErrUploadStalled    = errors.New("upload server has not acknowledged the chunk")
+	ErrUploadType       = errors.New("content does not match the upload type")
+	ErrUploadTooLarge   = errors.New("file exceeds the upload size limit")
+
+	ErrDownloadUnsupported = errors.New("attachment cannot be downloaded")
+	ErrDownloadTooLarge    = errors.New("attachment exceeds the download size limit")
+	ErrDownloadContentType = errors.New("unexpected content type of the attachment")
+
+	ErrCassetteNoMatch = errors.New("no recorded interaction matches the request")
 )
 
 type APIError struct {
@@ -73,3 +100,39 @@ func (e *SerializationError) Error() string {
 func (e *SerializationError) Unwrap() error {
 	return e.Err
 }
+
+// UploadItemError is the failure of one item of UploadMany.
+type UploadItemError struct {
+	Index int
+	Name  string
+	Err   error
+}
+
+func (e *UploadItemError) Error() string {
+	return fmt.Sprintf("upload #%d %q: %v", e.Index, e.Name, e.Err)
+}
+
+func (e *UploadItemError) Unwrap() error {
+	return e.Err
+}
+
+// BatchUploadError lists the items of UploadMany that have failed.
+type BatchUploadError struct {
+	Errors []*UploadItemError
+}
+
+func (e *BatchUploadError) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+	return fmt.Sprintf("%d uploads failed, first: %v", len(e.Errors), e.Errors[0])
+}
+
+func (e *BatchUploadError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+
+	return errs
+}
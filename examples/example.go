@@ -100,7 +100,7 @@ func main() {
 				continue
 			}
 
-			keyboard := api.Messages.NewKeyboardBuilder()
+			keyboard := maxbot.NewKeyboardBuilder()
 			keyboard.
 				AddRow().
 				AddGeolocation("Прислать геолокацию", true).
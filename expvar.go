@@ -0,0 +1,100 @@
+package maxbot
+
+import (
+	"expvar"
+	"strconv"
+	"time"
+
+	"github.com/pavmos/max-bot-api-client-go/schemes"
+)
+
+const unknownUpdateType = "unknown"
+
+// ExpvarMetrics publishes measurements as an expvar map, served as JSON by the /debug/vars handler of expvar:
+//
+//	"requests", "request_seconds": count and total latency by "METHOD path status"
+//	"polls", "poll_errors", "poll_seconds", "poll_updates": long polling requests, failures, total latency and updates
+//	"updates", "parse_failures": received updates by type and failed ones by type
+//	"handlers", "handler_errors", "handler_seconds": handler calls, failures and total latency by handler
+type ExpvarMetrics struct {
+	root *expvar.Map
+
+	requests       *expvar.Map
+	requestSeconds *expvar.Map
+	updates        *expvar.Map
+	parseFailures  *expvar.Map
+	handlers       *expvar.Map
+	handlerErrors  *expvar.Map
+	handlerSeconds *expvar.Map
+}
+
+// NewExpvarMetrics publishes the metrics under the name, e.g. the bot name when a process runs several bots.
+// Metrics created again with the same name continue the published counters. It panics if the name
+// is already published by another variable, like expvar.Publish does.
+func NewExpvarMetrics(name string) *ExpvarMetrics {
+	root, ok := expvar.Get(name).(*expvar.Map)
+	if !ok {
+		root = expvar.NewMap(name)
+	}
+
+	return &ExpvarMetrics{
+		root:           root,
+		requests:       expvarSubmap(root, "requests"),
+		requestSeconds: expvarSubmap(root, "request_seconds"),
+		updates:        expvarSubmap(root, "updates"),
+		parseFailures:  expvarSubmap(root, "parse_failures"),
+		handlers:       expvarSubmap(root, "handlers"),
+		handlerErrors:  expvarSubmap(root, "handler_errors"),
+		handlerSeconds: expvarSubmap(root, "handler_seconds"),
+	}
+}
+
+func expvarSubmap(root *expvar.Map, key string) *expvar.Map {
+	if m, ok := root.Get(key).(*expvar.Map); ok {
+		return m
+	}
+
+	m := new(expvar.Map)
+	root.Set(key, m)
+
+	return m
+}
+
+// ObserveRequest implements Metrics.
+func (m *ExpvarMetrics) ObserveRequest(method, path string, status int, latency time.Duration) {
+	key := method + " " + path + " " + strconv.Itoa(status)
+	m.requests.Add(key, 1)
+	m.requestSeconds.AddFloat(key, latency.Seconds())
+}
+
+// ObservePoll implements Metrics.
+func (m *ExpvarMetrics) ObservePoll(latency time.Duration, updates int, err error) {
+	m.root.Add("polls", 1)
+	if err != nil {
+		m.root.Add("poll_errors", 1)
+	}
+	m.root.AddFloat("poll_seconds", latency.Seconds())
+	m.root.Add("poll_updates", int64(updates))
+}
+
+// ObserveUpdate implements Metrics.
+func (m *ExpvarMetrics) ObserveUpdate(updateType schemes.UpdateType, err error) {
+	key := string(updateType)
+	if key == "" {
+		key = unknownUpdateType
+	}
+	if err != nil {
+		m.parseFailures.Add(key, 1)
+		return
+	}
+	m.updates.Add(key, 1)
+}
+
+// ObserveHandler implements Metrics.
+func (m *ExpvarMetrics) ObserveHandler(handler string, latency time.Duration, err error) {
+	m.handlers.Add(handler, 1)
+	if err != nil {
+		m.handlerErrors.Add(handler, 1)
+	}
+	m.handlerSeconds.AddFloat(handler, latency.Seconds())
+}
@@ -0,0 +1,174 @@
+package maxbot
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavmos/max-bot-api-client-go/schemes"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files in testdata")
+
+var fixtureUpdateTypes = map[schemes.UpdateType]reflect.Type{
+	schemes.TypeMessageCallback:  reflect.TypeOf(&schemes.MessageCallbackUpdate{}),
+	schemes.TypeMessageCreated:   reflect.TypeOf(&schemes.MessageCreatedUpdate{}),
+	schemes.TypeMessageRemoved:   reflect.TypeOf(&schemes.MessageRemovedUpdate{}),
+	schemes.TypeMessageEdited:    reflect.TypeOf(&schemes.MessageEditedUpdate{}),
+	schemes.TypeBotAdded:         reflect.TypeOf(&schemes.BotAddedToChatUpdate{}),
+	schemes.TypeBotRemoved:       reflect.TypeOf(&schemes.BotRemovedFromChatUpdate{}),
+	schemes.TypeUserAdded:        reflect.TypeOf(&schemes.UserAddedToChatUpdate{}),
+	schemes.TypeUserRemoved:      reflect.TypeOf(&schemes.UserRemovedFromChatUpdate{}),
+	schemes.TypeBotStarted:       reflect.TypeOf(&schemes.BotStartedUpdate{}),
+	schemes.TypeChatTitleChanged: reflect.TypeOf(&schemes.ChatTitleChangedUpdate{}),
+}
+
+var fixtureAttachmentTypes = map[schemes.AttachmentType]reflect.Type{
+	schemes.AttachmentImage:    reflect.TypeOf(&schemes.PhotoAttachment{}),
+	schemes.AttachmentVideo:    reflect.TypeOf(&schemes.VideoAttachment{}),
+	schemes.AttachmentAudio:    reflect.TypeOf(&schemes.AudioAttachment{}),
+	schemes.AttachmentFile:     reflect.TypeOf(&schemes.FileAttachment{}),
+	schemes.AttachmentContact:  reflect.TypeOf(&schemes.ContactAttachment{}),
+	schemes.AttachmentSticker:  reflect.TypeOf(&schemes.StickerAttachment{}),
+	schemes.AttachmentShare:    reflect.TypeOf(&schemes.ShareAttachment{}),
+	schemes.AttachmentLocation: reflect.TypeOf(&schemes.LocationAttachment{}),
+	schemes.AttachmentKeyboard: reflect.TypeOf(&schemes.InlineKeyboardAttachment{}),
+}
+
+// readFixtures returns the content of the JSON fixtures in the directory by file name.
+func readFixtures(t testing.TB, dir string) map[string][]byte {
+	t.Helper()
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	fixtures := make(map[string][]byte, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		fixtures[file] = data
+	}
+
+	return fixtures
+}
+
+// checkGolden compares the value encoded as indented JSON with the golden file next to the fixture.
+// Run tests with -update to rewrite golden files after an intended change.
+func checkGolden(t *testing.T, fixture string, value any) {
+	t.Helper()
+
+	got, err := json.MarshalIndent(value, "", "  ")
+	require.NoError(t, err)
+	got = append(got, '\n')
+
+	golden := strings.TrimSuffix(fixture, ".json") + ".golden"
+	if *updateGolden {
+		require.NoError(t, os.WriteFile(golden, got, 0o644))
+	}
+
+	want, err := os.ReadFile(golden)
+	require.NoError(t, err, "golden file is missing, run tests with -update")
+	require.Equal(t, string(want), string(got))
+}
+
+func TestUpdateFixtures(t *testing.T) {
+	api, err := New("test")
+	require.NoError(t, err)
+
+	covered := make(map[schemes.UpdateType]bool)
+	for file, data := range readFixtures(t, "testdata/updates") {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			update, err := api.bytesToProperUpdate(data)
+			require.NoError(t, err)
+			require.Equal(t, fixtureUpdateTypes[update.GetUpdateType()], reflect.TypeOf(update))
+			covered[update.GetUpdateType()] = true
+
+			checkGolden(t, file, update)
+		})
+	}
+
+	for updateType := range fixtureUpdateTypes {
+		require.True(t, covered[updateType], "no fixture for %s", updateType)
+	}
+}
+
+func TestAttachmentFixtures(t *testing.T) {
+	api, err := New("test")
+	require.NoError(t, err)
+
+	covered := make(map[schemes.AttachmentType]bool)
+	for file, data := range readFixtures(t, "testdata/attachments") {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			attachments, err := api.ConvertRawAttachments([]json.RawMessage{data})
+			require.NoError(t, err)
+			require.Len(t, attachments, 1)
+
+			attachment := attachments[0].(schemes.AttachmentInterface)
+			require.Equal(t, fixtureAttachmentTypes[attachment.GetAttachmentType()], reflect.TypeOf(attachment))
+			covered[attachment.GetAttachmentType()] = true
+
+			checkGolden(t, file, attachment)
+		})
+	}
+
+	for attachmentType := range fixtureAttachmentTypes {
+		require.True(t, covered[attachmentType], "no fixture for %s", attachmentType)
+	}
+}
+
+func FuzzBytesToProperUpdate(f *testing.F) {
+	for _, data := range readFixtures(f, "testdata/updates") {
+		f.Add(data)
+	}
+	f.Add([]byte(`{"update_type":"message_created","message":null}`))
+	f.Add([]byte(`{"update_type":"message_callback","message":{"body":{"attachments":[{"type":"inline_keyboard","payload":{"buttons":[[null]]}}]}}}`))
+
+	api, err := New("test")
+	require.NoError(f, err)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		update, err := api.bytesToProperUpdate(data)
+		if err != nil {
+			return
+		}
+		if _, ok := fixtureUpdateTypes[update.GetUpdateType()]; !ok {
+			t.Fatalf("unknown update type %q decoded without error", update.GetUpdateType())
+		}
+		update.GetUserID()
+		update.GetChatID()
+		if _, err := json.Marshal(update); err != nil {
+			t.Fatalf("decoded update cannot be encoded: %v", err)
+		}
+	})
+}
+
+func FuzzConvertRawAttachments(f *testing.F) {
+	for _, data := range readFixtures(f, "testdata/attachments") {
+		f.Add(data)
+	}
+	f.Add([]byte(`{"type":"inline_keyboard","payload":{"buttons":[[{"type":"callback"}],null]}}`))
+	f.Add([]byte(`{"type":"contact","payload":{"max_info":null}}`))
+
+	api, err := New("test")
+	require.NoError(f, err)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		attachments, err := api.ConvertRawAttachments([]json.RawMessage{data})
+		if err != nil {
+			return
+		}
+		if len(attachments) != 1 {
+			t.Fatalf("got %d attachments, want 1", len(attachments))
+		}
+		if _, ok := attachments[0].(schemes.AttachmentInterface); !ok {
+			t.Fatalf("attachment %T does not implement AttachmentInterface", attachments[0])
+		}
+	})
+}
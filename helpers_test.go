@@ -0,0 +1,28 @@
+package maxbot
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestApi returns a client sending requests to the test server.
+func newTestApi(t testing.TB, serverURL string) *Api {
+	t.Helper()
+
+	return newTestApiWithToken(t, "test", serverURL)
+}
+
+// newTestApiWithToken works like newTestApi for the bot with the given token.
+func newTestApiWithToken(t testing.TB, token, serverURL string) *Api {
+	t.Helper()
+
+	api, err := New(token)
+	require.NoError(t, err)
+	u, err := url.Parse(serverURL + "/")
+	require.NoError(t, err)
+	api.client.baseURL = u
+
+	return api
+}
@@ -0,0 +1,246 @@
+package maxbot
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif" // register the decoder for image.Decode
+	"image/jpeg"
+	_ "image/png" // register the decoder for image.Decode
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	defaultJPEGQuality = 85
+	defaultMaxPixels   = 50_000_000
+
+	exifOrientationTag = 0x0112
+)
+
+// ImageProcessing describes how photos are prepared before the upload: decoded, downscaled and re-encoded as JPEG.
+// Re-encoding drops the metadata of the original file, such as EXIF, so the EXIF orientation of JPEG photos
+// is applied to the pixels first.
+type ImageProcessing struct {
+	MaxDimension int // Largest width or height, bigger images are downscaled keeping the aspect ratio; zero keeps the size
+	Quality      int // JPEG quality from 1 to 100, 85 by default
+	MaxPixels    int // Largest width × height of the original, bigger images are rejected before decoding; 50 million by default
+}
+
+func (p ImageProcessing) quality() int {
+	if p.Quality <= 0 || p.Quality > 100 {
+		return defaultJPEGQuality
+	}
+
+	return p.Quality
+}
+
+func (p ImageProcessing) maxPixels() int {
+	if p.MaxPixels <= 0 {
+		return defaultMaxPixels
+	}
+
+	return p.MaxPixels
+}
+
+// cacheSuffix distinguishes cached results of the same original processed differently.
+func (p ImageProcessing) cacheSuffix() string {
+	return fmt.Sprintf(":jpeg%d:%dpx", p.quality(), p.MaxDimension)
+}
+
+// process decodes the PNG, JPEG or GIF image and returns it re-encoded as JPEG with the name changed accordingly.
+// The size of the image is checked against MaxPixels before decoding, as a small file may hold a huge image.
+func (p ImageProcessing) process(reader io.Reader, fileName string) (*bytes.Reader, string, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, "", err
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: failed to decode image: %v", ErrUploadType, err)
+	}
+	if int64(config.Width)*int64(config.Height) > int64(p.maxPixels()) {
+		return nil, "", fmt.Errorf("%w: image is %dx%d, more than %d pixels", ErrUploadTooLarge,
+			config.Width, config.Height, p.maxPixels())
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: failed to decode image: %v", ErrUploadType, err)
+	}
+
+	img := downscale(orient(flatten(src), jpegOrientation(data)), p.MaxDimension)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: p.quality()}); err != nil {
+		return nil, "", fmt.Errorf("failed to encode image: %w", err)
+	}
+
+	if fileName != "" {
+		fileName = strings.TrimSuffix(fileName, filepath.Ext(fileName)) + ".jpg"
+	}
+
+	return bytes.NewReader(buf.Bytes()), fileName, nil
+}
+
+// flatten draws the image over a white background, as JPEG has no transparency.
+func flatten(src image.Image) *image.RGBA {
+	bounds := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), src, bounds.Min, draw.Over)
+
+	return dst
+}
+
+// downscale shrinks the image so that neither side exceeds maxDimension, averaging the source pixels covered
+// by every destination pixel. Smaller images are returned as is.
+func downscale(src *image.RGBA, maxDimension int) *image.RGBA {
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	if maxDimension <= 0 || sw <= maxDimension && sh <= maxDimension {
+		return src
+	}
+
+	dw, dh := maxDimension, maxDimension
+	if sw >= sh {
+		dh = max(sh*maxDimension/sw, 1)
+	} else {
+		dw = max(sw*maxDimension/sh, 1)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := range dh {
+		sy0, sy1 := y*sh/dh, max((y+1)*sh/dh, y*sh/dh+1)
+		for x := range dw {
+			sx0, sx1 := x*sw/dw, max((x+1)*sw/dw, x*sw/dw+1)
+
+			var r, g, b, n int
+			for sy := sy0; sy < sy1; sy++ {
+				row := src.Pix[sy*src.Stride+sx0*4 : sy*src.Stride+sx1*4]
+				for i := 0; i < len(row); i += 4 {
+					r += int(row[i])
+					g += int(row[i+1])
+					b += int(row[i+2])
+					n++
+				}
+			}
+
+			offset := y*dst.Stride + x*4
+			dst.Pix[offset] = uint8(r / n)
+			dst.Pix[offset+1] = uint8(g / n)
+			dst.Pix[offset+2] = uint8(b / n)
+			dst.Pix[offset+3] = 0xff
+		}
+	}
+
+	return dst
+}
+
+// orient transforms the image according to the EXIF orientation, so it is shown upright without the tag.
+func orient(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := range h {
+		for x := range w {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirrored horizontally
+				dx, dy = w-1-x, y
+			case 3: // rotated by 180°
+				dx, dy = w-1-x, h-1-y
+			case 4: // mirrored vertically
+				dx, dy = x, h-1-y
+			case 5: // mirrored along the main diagonal
+				dx, dy = y, x
+			case 6: // rotated by 90° clockwise to display
+				dx, dy = h-1-y, x
+			case 7: // mirrored along the anti-diagonal
+				dx, dy = h-1-y, w-1-x
+			case 8: // rotated by 90° counterclockwise to display
+				dx, dy = y, w-1-x
+			}
+			copy(dst.Pix[dy*dst.Stride+dx*4:dy*dst.Stride+dx*4+4], src.Pix[y*src.Stride+x*4:y*src.Stride+x*4+4])
+		}
+	}
+
+	return dst
+}
+
+// jpegOrientation returns the EXIF orientation of the JPEG image from 1 to 8, or 1 if there is none.
+func jpegOrientation(data []byte) int {
+	if len(data) < 2 || data[0] != 0xff || data[1] != 0xd8 {
+		return 1
+	}
+
+	// Walk the marker segments up to the start of the image data.
+	for i := 2; i+4 <= len(data) && data[i] == 0xff; {
+		marker := data[i+1]
+		if marker == 0xda || marker == 0xd9 {
+			break
+		}
+
+		size := int(binary.BigEndian.Uint16(data[i+2:]))
+		if size < 2 || i+2+size > len(data) {
+			break
+		}
+		segment := data[i+4 : i+2+size]
+		if marker == 0xe1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+		i += 2 + size
+	}
+
+	return 1
+}
+
+// exifOrientation reads the orientation tag from the first IFD of the EXIF TIFF structure.
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int64(order.Uint32(tiff[4:]))
+	if ifd+2 > int64(len(tiff)) {
+		return 1
+	}
+
+	entries := int64(order.Uint16(tiff[ifd:]))
+	for i := range entries {
+		entry := ifd + 2 + i*12
+		if entry+12 > int64(len(tiff)) {
+			break
+		}
+		if order.Uint16(tiff[entry:]) == exifOrientationTag {
+			if orientation := int(order.Uint16(tiff[entry+8:])); orientation >= 1 && orientation <= 8 {
+				return orientation
+			}
+			break
+		}
+	}
+
+	return 1
+}
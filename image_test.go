@@ -0,0 +1,146 @@
+package maxbot
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestImage returns a w×h image: the left half is opaque red, the right half is transparent.
+func newTestImage(w, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w / 2 {
+			img.SetNRGBA(x, y, color.NRGBA{R: 0xff, A: 0xff})
+		}
+	}
+
+	return img
+}
+
+func TestImageProcessing(t *testing.T) {
+	var original bytes.Buffer
+	require.NoError(t, png.Encode(&original, newTestImage(1200, 600)))
+
+	processed, name, err := ImageProcessing{MaxDimension: 400, Quality: 90}.process(bytes.NewReader(original.Bytes()), "scans/page.png")
+	require.NoError(t, err)
+	require.Equal(t, "scans/page.jpg", name)
+
+	img, format, err := image.Decode(processed)
+	require.NoError(t, err)
+	require.Equal(t, "jpeg", format)
+	require.Equal(t, image.Rect(0, 0, 400, 200), img.Bounds())
+
+	assertColor := func(x, y int, want color.RGBA) {
+		r, g, b, _ := img.At(x, y).RGBA()
+		require.InDelta(t, want.R, r>>8, 8)
+		require.InDelta(t, want.G, g>>8, 8)
+		require.InDelta(t, want.B, b>>8, 8)
+	}
+	assertColor(100, 100, color.RGBA{R: 0xff})
+	assertColor(300, 100, color.RGBA{R: 0xff, G: 0xff, B: 0xff})
+}
+
+func TestImageProcessing_StripsMetadata(t *testing.T) {
+	var encoded bytes.Buffer
+	require.NoError(t, jpeg.Encode(&encoded, newTestImage(100, 50), nil))
+
+	// Insert an EXIF segment after the SOI marker
+	exif := append([]byte{0xff, 0xe1, 0x00, 0x10}, []byte("Exif\x00\x00GPS-DATA")...)
+	withExif := append(append(append([]byte{}, encoded.Bytes()[:2]...), exif...), encoded.Bytes()[2:]...)
+
+	processed, _, err := ImageProcessing{}.process(bytes.NewReader(withExif), "")
+	require.NoError(t, err)
+
+	data := make([]byte, processed.Len())
+	processed.Read(data)
+	require.NotContains(t, string(data), "Exif")
+	require.NotContains(t, string(data), "GPS-DATA")
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, image.Rect(0, 0, 100, 50), img.Bounds(), "small images keep the size")
+}
+
+// withExifOrientation inserts an EXIF segment with the orientation tag after the SOI marker of the JPEG.
+func withExifOrientation(jpegData []byte, orientation byte) []byte {
+	tiff := []byte{'M', 'M', 0, 0x2a, 0, 0, 0, 8, // big-endian header, the first IFD at offset 8
+		0, 1, // one entry
+		0x01, 0x12, 0, 3, 0, 0, 0, 1, 0, orientation, 0, 0, // orientation, SHORT, count 1
+		0, 0, 0, 0} // no next IFD
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	header := []byte{0xff, 0xe1, 0, byte(len(segment) + 2)}
+
+	return append(append(append(append([]byte{}, jpegData[:2]...), header...), segment...), jpegData[2:]...)
+}
+
+func TestImageProcessing_Orientation(t *testing.T) {
+	// JPEG has no transparency, the right half of the test image is black.
+	var encoded bytes.Buffer
+	require.NoError(t, jpeg.Encode(&encoded, newTestImage(100, 50), &jpeg.Options{Quality: 100}))
+
+	tests := []struct {
+		orientation byte
+		wantBounds  image.Rectangle
+		redAt       image.Point
+		blackAt     image.Point
+	}{
+		{orientation: 1, wantBounds: image.Rect(0, 0, 100, 50), redAt: image.Pt(10, 25), blackAt: image.Pt(90, 25)},
+		{orientation: 3, wantBounds: image.Rect(0, 0, 100, 50), redAt: image.Pt(90, 25), blackAt: image.Pt(10, 25)},
+		{orientation: 6, wantBounds: image.Rect(0, 0, 50, 100), redAt: image.Pt(25, 10), blackAt: image.Pt(25, 90)},
+		{orientation: 8, wantBounds: image.Rect(0, 0, 50, 100), redAt: image.Pt(25, 90), blackAt: image.Pt(25, 10)},
+	}
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(int(tt.orientation)), func(t *testing.T) {
+			data := withExifOrientation(encoded.Bytes(), tt.orientation)
+			require.Equal(t, int(tt.orientation), jpegOrientation(data))
+
+			processed, _, err := ImageProcessing{Quality: 100}.process(bytes.NewReader(data), "")
+			require.NoError(t, err)
+			img, _, err := image.Decode(processed)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantBounds, img.Bounds())
+
+			r, _, _, _ := img.At(tt.redAt.X, tt.redAt.Y).RGBA()
+			require.Greater(t, r>>8, uint32(0xe0), "red pixel expected")
+			r, _, _, _ = img.At(tt.blackAt.X, tt.blackAt.Y).RGBA()
+			require.Less(t, r>>8, uint32(0x20), "black pixel expected")
+		})
+	}
+}
+
+func TestImageProcessing_MaxPixels(t *testing.T) {
+	var original bytes.Buffer
+	require.NoError(t, png.Encode(&original, image.NewGray(image.Rect(0, 0, 2000, 1000))))
+
+	_, _, err := ImageProcessing{MaxPixels: 1_000_000}.process(bytes.NewReader(original.Bytes()), "")
+	require.ErrorIs(t, err, ErrUploadTooLarge)
+
+	_, _, err = ImageProcessing{MaxPixels: 2_000_000}.process(bytes.NewReader(original.Bytes()), "")
+	require.NoError(t, err)
+}
+
+func TestUploadPhotoWithImageProcessing(t *testing.T) {
+	server := newUploadServer(t)
+	api := newTestApi(t, server.URL)
+
+	var original bytes.Buffer
+	require.NoError(t, png.Encode(&original, newTestImage(1600, 1200)))
+
+	_, err := api.Uploads.UploadPhotoFromReaderWithName(context.Background(), bytes.NewReader(original.Bytes()), "scan.png",
+		WithImageProcessing(ImageProcessing{MaxDimension: 640}))
+	require.NoError(t, err)
+	require.Equal(t, "scan.jpg", server.fileName)
+	require.Less(t, server.received, int64(original.Len()))
+
+	_, err = api.Uploads.UploadPhotoFromReader(context.Background(), bytes.NewReader([]byte("not an image")),
+		WithImageProcessing(ImageProcessing{}))
+	require.ErrorIs(t, err, ErrUploadType)
+}
@@ -0,0 +1,88 @@
+package maxbot
+
+import (
+	"context"
+	"io"
+	"net/url"
+
+	"github.com/pavmos/max-bot-api-client-go/schemes"
+)
+
+//go:generate mockgen -source=interfaces.go -destination=./mocks/maxbot_mock.go -package=mocks
+
+// BotsAPI is the bot info API, see Api.Bots. Like the other sub-client interfaces it lets business code
+// substitute the generated mocks from the mocks package in unit tests, so the interfaces hold only requests
+// to the API: the settings of the sub-clients are changed on Api and the helpers are created by package functions.
+type BotsAPI interface {
+	GetBot(ctx context.Context) (*schemes.BotInfo, error)
+	PatchBot(ctx context.Context, patch *schemes.BotPatch) (*schemes.BotInfo, error)
+}
+
+// ChatsAPI is the chats and members API, see Api.Chats.
+type ChatsAPI interface {
+	GetChats(ctx context.Context, count, marker int64) (*schemes.ChatList, error)
+	GetChat(ctx context.Context, chatID int64) (*schemes.Chat, error)
+	GetChatMembership(ctx context.Context, chatID int64) (*schemes.ChatMember, error)
+	GetChatMembers(ctx context.Context, chatID, count, marker int64) (*schemes.ChatMembersList, error)
+	GetSpecificChatMembers(ctx context.Context, chatID int64, userIDs []int64) (*schemes.ChatMembersList, error)
+	GetChatAdmins(ctx context.Context, chatID int64) (*schemes.ChatMembersList, error)
+	LeaveChat(ctx context.Context, chatID int64) (*schemes.SimpleQueryResult, error)
+	EditChat(ctx context.Context, chatID int64, update *schemes.ChatPatch) (*schemes.Chat, error)
+	AddMember(ctx context.Context, chatID int64, users schemes.UserIdsList) (*schemes.SimpleQueryResult, error)
+	RemoveMember(ctx context.Context, chatID int64, userID int64) (*schemes.SimpleQueryResult, error)
+	SendAction(ctx context.Context, chatID int64, action schemes.SenderAction) (*schemes.SimpleQueryResult, error)
+}
+
+// DebugsAPI sends updates and errors to the debug chat, see Api.Debugs.
+type DebugsAPI interface {
+	Send(ctx context.Context, upd schemes.UpdateInterface) error
+	SendErr(ctx context.Context, err error) error
+}
+
+// MessagesAPI is the messages API, see Api.Messages.
+type MessagesAPI interface {
+	GetMessages(ctx context.Context, chatID int64, messageIDs []string, from int, to int, count int) (*schemes.MessageList, error)
+	GetMessage(ctx context.Context, messageID string) (*schemes.Message, error)
+	EditMessage(ctx context.Context, messageID string, message *Message) error
+	DeleteMessage(ctx context.Context, messageID string) (*schemes.SimpleQueryResult, error)
+	AnswerOnCallback(ctx context.Context, callbackID string, callback *schemes.CallbackAnswer) (*schemes.SimpleQueryResult, error)
+	Send(ctx context.Context, m *Message) error
+	SendWithResult(ctx context.Context, m *Message) (*schemes.Message, error)
+	SendWhenReady(ctx context.Context, m *Message) (*schemes.Message, error)
+	GetVideoDetails(ctx context.Context, videoToken string) (*schemes.VideoAttachmentDetails, error)
+	Check(ctx context.Context, m *Message) (bool, error)
+	ListExist(ctx context.Context, m *Message) ([]string, error)
+}
+
+// SubscriptionsAPI is the webhook subscriptions API, see Api.Subscriptions.
+type SubscriptionsAPI interface {
+	GetSubscriptions(ctx context.Context) (*schemes.GetSubscriptionsResult, error)
+	Subscribe(ctx context.Context, subscribeURL string, updateTypes []string, secret string) (*schemes.SimpleQueryResult, error)
+	Unsubscribe(ctx context.Context, subscriptionURL string) (*schemes.SimpleQueryResult, error)
+}
+
+// UploadsAPI is the uploads API, see Api.Uploads.
+type UploadsAPI interface {
+	UploadMediaFromFile(ctx context.Context, uploadType schemes.UploadType, filename string, opts ...UploadOption) (*schemes.UploadedInfo, error)
+	UploadMediaFromUrl(ctx context.Context, uploadType schemes.UploadType, u url.URL, opts ...UploadOption) (*schemes.UploadedInfo, error)
+	UploadMediaFromReader(ctx context.Context, uploadType schemes.UploadType, reader io.Reader, opts ...UploadOption) (*schemes.UploadedInfo, error)
+	UploadMediaFromReaderWithName(ctx context.Context, uploadType schemes.UploadType, reader io.Reader, name string, opts ...UploadOption) (*schemes.UploadedInfo, error)
+	UploadMediaFromFileChunked(ctx context.Context, uploadType schemes.UploadType, filename string, opts ...UploadOption) (*schemes.UploadedInfo, error)
+	UploadMediaChunked(ctx context.Context, uploadType schemes.UploadType, reader io.ReaderAt, size int64, name string, opts ...UploadOption) (*schemes.UploadedInfo, error)
+	UploadPhotoFromFile(ctx context.Context, fileName string, opts ...UploadOption) (*schemes.PhotoTokens, error)
+	UploadPhotoFromBase64String(ctx context.Context, code string, opts ...UploadOption) (*schemes.PhotoTokens, error)
+	UploadPhotoFromUrl(ctx context.Context, url string, opts ...UploadOption) (*schemes.PhotoTokens, error)
+	UploadPhotoFromReader(ctx context.Context, reader io.Reader, opts ...UploadOption) (*schemes.PhotoTokens, error)
+	UploadPhotoFromReaderWithName(ctx context.Context, reader io.Reader, name string, opts ...UploadOption) (*schemes.PhotoTokens, error)
+	UploadMany(ctx context.Context, items []UploadItem, opts ...UploadOption) ([]any, error)
+	UploadAuto(ctx context.Context, filename string, opts ...UploadOption) (any, error)
+}
+
+var (
+	_ BotsAPI          = (*bots)(nil)
+	_ ChatsAPI         = (*chats)(nil)
+	_ DebugsAPI        = (*debugs)(nil)
+	_ MessagesAPI      = (*messages)(nil)
+	_ SubscriptionsAPI = (*subscriptions)(nil)
+	_ UploadsAPI       = (*uploads)(nil)
+)
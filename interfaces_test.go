@@ -0,0 +1,56 @@
+package maxbot_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/pavmos/max-bot-api-client-go"
+	"github.com/pavmos/max-bot-api-client-go/mocks"
+	"github.com/pavmos/max-bot-api-client-go/schemes"
+)
+
+// greet is business code depending on the sub-client interface only.
+func greet(ctx context.Context, messages maxbot.MessagesAPI, chats maxbot.ChatsAPI, chatID int64) error {
+	chat, err := chats.GetChat(ctx, chatID)
+	if err != nil {
+		return err
+	}
+
+	return messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText("Hello, "+chat.Title))
+}
+
+func TestMocks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	messages := mocks.NewMockMessagesAPI(ctrl)
+	chats := mocks.NewMockChatsAPI(ctrl)
+
+	api, err := maxbot.New("test")
+	require.NoError(t, err)
+	api.Messages = messages
+	api.Chats = chats
+
+	ctx := context.Background()
+	chats.EXPECT().GetChat(ctx, int64(1)).Return(&schemes.Chat{ChatId: 1, Title: "team"}, nil)
+	messages.EXPECT().Send(ctx, gomock.Any()).Return(nil)
+	require.NoError(t, greet(ctx, api.Messages, api.Chats, 1))
+
+	// Helpers built on the sub-clients work with the mocks as well.
+	menu := maxbot.NewPaginator("menu", []maxbot.PaginatorItem{{Text: "a", Payload: "a"}, {Text: "b", Payload: "b"}}).SetPerPage(1)
+	upd := &schemes.MessageCallbackUpdate{
+		Callback: schemes.Callback{CallbackID: "callback", Payload: menu.PagePayload(1)},
+		Message:  &schemes.Message{Body: schemes.MessageBody{Mid: "mid.1", Text: "menu"}},
+	}
+	messages.EXPECT().
+		AnswerOnCallback(ctx, "callback", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, answer *schemes.CallbackAnswer) (*schemes.SimpleQueryResult, error) {
+			require.Equal(t, "menu", answer.Message.Text)
+			return &schemes.SimpleQueryResult{Success: true}, nil
+		})
+
+	handled, err := menu.HandleCallback(ctx, api.Messages, upd)
+	require.NoError(t, err)
+	require.True(t, handled)
+}
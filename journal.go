@@ -0,0 +1,182 @@
+package maxbot
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pavmos/max-bot-api-client-go/schemes"
+)
+
+// JournalEntry is a raw update received by the bot at the time.
+type JournalEntry struct {
+	Time   time.Time       `json:"time"`
+	Update json.RawMessage `json:"update"`
+}
+
+// JournalWriter appends received updates to a JSONL journal, one entry per line.
+type JournalWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+	c  io.Closer
+}
+
+// NewJournalWriter returns a journal writing entries to w.
+func NewJournalWriter(w io.Writer) *JournalWriter {
+	return &JournalWriter{w: w}
+}
+
+// OpenJournal opens the journal file for appending, creating it if needed.
+func OpenJournal(filename string) (*JournalWriter, error) {
+	fh, err := os.OpenFile(filename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &JournalWriter{w: fh, c: fh}, nil
+}
+
+// Write appends the raw update received now.
+func (j *JournalWriter) Write(update []byte) error {
+	return j.WriteEntry(JournalEntry{Time: time.Now(), Update: update})
+}
+
+// WriteEntry appends the entry. The update must be valid JSON.
+func (j *JournalWriter) WriteEntry(entry JournalEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return &SerializationError{Op: "marshal", Type: "journal entry", Err: err}
+	}
+	data = append(data, '\n')
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	_, err = j.w.Write(data)
+
+	return err
+}
+
+// Close closes the journal file opened by OpenJournal.
+func (j *JournalWriter) Close() error {
+	if j.c == nil {
+		return nil
+	}
+
+	return j.c.Close()
+}
+
+// JournalReader reads entries of a journal written by JournalWriter.
+type JournalReader struct {
+	r *bufio.Reader
+}
+
+// NewJournalReader returns a reader of the journal.
+func NewJournalReader(r io.Reader) *JournalReader {
+	return &JournalReader{r: bufio.NewReader(r)}
+}
+
+// Next returns the next entry or io.EOF at the end of the journal. Empty lines are skipped.
+func (j *JournalReader) Next() (*JournalEntry, error) {
+	for {
+		line, err := j.r.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) == 0 {
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+
+		entry := new(JournalEntry)
+		if err := json.Unmarshal(line, entry); err != nil {
+			return nil, &SerializationError{Op: "unmarshal", Type: "journal entry", Err: err}
+		}
+
+		return entry, nil
+	}
+}
+
+// SetJournal sets the journal recording raw updates received by GetUpdates and GetHandler, nil disables recording.
+func (a *Api) SetJournal(journal *JournalWriter) {
+	a.journal = journal
+}
+
+func (a *Api) record(data []byte) {
+	if a.journal == nil {
+		return
+	}
+	if err := a.journal.Write(data); err != nil {
+		a.client.logger.Error("failed to record update", "error", err)
+	}
+}
+
+// ReplayJournal returns a channel that delivers updates of the journal the same way GetUpdates does, keeping
+// the original intervals between them divided by the speed set by WithSpeed. The channel is closed at the end
+// of the journal or when the context is done. Entries that cannot be read or parsed are logged and skipped.
+func (a *Api) ReplayJournal(ctx context.Context, r io.Reader, opts ...ReplayOption) <-chan schemes.UpdateInterface {
+	options := newReplayOptions(opts)
+	ch := make(chan schemes.UpdateInterface, 100)
+
+	go func() {
+		defer close(ch)
+
+		reader := NewJournalReader(r)
+		var first time.Time
+		start := time.Now()
+
+		for {
+			entry, err := reader.Next()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				var serErr *SerializationError
+				if errors.As(err, &serErr) {
+					a.client.logger.Warn("failed to read journal entry", "error", err)
+					continue
+				}
+				a.client.logger.Error("failed to read journal", "error", err)
+				return
+			}
+
+			if first.IsZero() {
+				first = entry.Time
+			}
+			if options.speed > 0 {
+				delay := time.Duration(float64(entry.Time.Sub(first))/options.speed) - time.Since(start)
+				if delay > 0 {
+					timer := time.NewTimer(delay)
+					select {
+					case <-ctx.Done():
+						timer.Stop()
+						return
+					case <-timer.C:
+					}
+				}
+			}
+
+			update, err := a.bytesToProperUpdate(entry.Update)
+			if err != nil {
+				a.client.logger.Warn("failed to parse journal update", "error", err)
+				continue
+			}
+
+			select {
+			case ch <- update:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch
+}
@@ -0,0 +1,112 @@
+package maxbot
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavmos/max-bot-api-client-go/schemes"
+)
+
+const (
+	journalMessage = `{"update_type":"message_created","timestamp":1,"message":{"recipient":{"chat_id":1},"body":{"mid":"mid.1","text":"hi"}}}`
+	journalStarted = `{"update_type":"bot_started","timestamp":2,"chat_id":1,"user":{"user_id":2}}`
+)
+
+func TestJournal_Record(t *testing.T) {
+	api, err := New("test")
+	require.NoError(t, err)
+
+	fileName := filepath.Join(t.TempDir(), "updates.jsonl")
+	journal, err := OpenJournal(fileName)
+	require.NoError(t, err)
+	api.SetJournal(journal)
+
+	updates := make(chan schemes.UpdateInterface, 2)
+	handler := api.GetHandler(updates)
+	for _, body := range []string{journalMessage, journalStarted} {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+		require.Equal(t, http.StatusOK, rec.Code)
+	}
+	require.NoError(t, journal.Close())
+
+	// The journal is appended to.
+	journal, err = OpenJournal(fileName)
+	require.NoError(t, err)
+	require.NoError(t, journal.Write([]byte(journalMessage)))
+	require.NoError(t, journal.Close())
+
+	fh, err := os.Open(fileName)
+	require.NoError(t, err)
+	defer fh.Close()
+	reader := NewJournalReader(fh)
+	var got []string
+	for {
+		entry, err := reader.Next()
+		if err != nil {
+			break
+		}
+		require.False(t, entry.Time.IsZero())
+		got = append(got, string(entry.Update))
+	}
+	require.Equal(t, []string{journalMessage, journalStarted, journalMessage}, got)
+}
+
+func TestJournal_Replay(t *testing.T) {
+	api, err := New("test")
+	require.NoError(t, err)
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	journal := NewJournalWriter(&buf)
+	require.NoError(t, journal.WriteEntry(JournalEntry{Time: start, Update: []byte(journalMessage)}))
+	buf.WriteString("{broken\n\n")
+	require.NoError(t, journal.WriteEntry(JournalEntry{Time: start.Add(time.Second), Update: []byte(journalStarted)}))
+
+	tests := []struct {
+		name    string
+		speed   float64
+		minTime time.Duration
+		maxTime time.Duration
+	}{
+		{name: "instant", speed: 0, maxTime: 200 * time.Millisecond},
+		{name: "accelerated", speed: 5, minTime: 200 * time.Millisecond, maxTime: time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			begin := time.Now()
+			var got []schemes.UpdateType
+			for upd := range api.ReplayJournal(context.Background(), bytes.NewReader(buf.Bytes()), WithSpeed(tt.speed)) {
+				got = append(got, upd.GetUpdateType())
+			}
+			elapsed := time.Since(begin)
+
+			require.Equal(t, []schemes.UpdateType{schemes.TypeMessageCreated, schemes.TypeBotStarted}, got)
+			require.GreaterOrEqual(t, elapsed, tt.minTime)
+			require.Less(t, elapsed, tt.maxTime)
+		})
+	}
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		updates := api.ReplayJournal(ctx, bytes.NewReader(buf.Bytes()))
+		<-updates
+		cancel()
+
+		select {
+		case _, ok := <-updates:
+			require.False(t, ok)
+		case <-time.After(500 * time.Millisecond):
+			t.Fatal("replay has not stopped")
+		}
+	})
+}
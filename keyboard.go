@@ -7,6 +7,13 @@ type Keyboard struct {
 	rows []*KeyboardRow
 }
 
+// NewKeyboardBuilder returns a new keyboard builder helper.
+func NewKeyboardBuilder() *Keyboard {
+	return &Keyboard{
+		rows: make([]*KeyboardRow, 0),
+	}
+}
+
 // AddRow adds a row to the inline keyboard.
 func (k *Keyboard) AddRow() *KeyboardRow {
 	kr := &KeyboardRow{}
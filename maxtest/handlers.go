@@ -0,0 +1,925 @@
+package maxtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pavmos/max-bot-api-client-go/schemes"
+)
+
+// contentRangePattern matches Content-Range of chunked uploads: "bytes 0-1023/4096".
+var contentRangePattern = regexp.MustCompile(`^bytes (\d+)-(\d+)/(\d+)$`)
+
+// apiError is the error response of the API.
+type apiError struct {
+	status  int
+	code    string
+	message string
+}
+
+func errorf(status int, code string, format string, args ...any) *apiError {
+	return &apiError{status: status, code: code, message: fmt.Sprintf(format, args...)}
+}
+
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /me", s.api(s.getMe))
+	mux.HandleFunc("PATCH /me", s.api(s.patchMe))
+
+	mux.HandleFunc("GET /chats", s.api(s.getChats))
+	mux.HandleFunc("GET /chats/{chatID}", s.api(s.getChat))
+	mux.HandleFunc("PATCH /chats/{chatID}", s.api(s.patchChat))
+	mux.HandleFunc("POST /chats/{chatID}/actions", s.api(s.postAction))
+	mux.HandleFunc("GET /chats/{chatID}/members", s.api(s.getMembers))
+	mux.HandleFunc("POST /chats/{chatID}/members", s.api(s.addMembers))
+	mux.HandleFunc("DELETE /chats/{chatID}/members", s.api(s.removeMember))
+	mux.HandleFunc("GET /chats/{chatID}/members/me", s.api(s.getMembership))
+	mux.HandleFunc("DELETE /chats/{chatID}/members/me", s.api(s.leaveChat))
+	mux.HandleFunc("GET /chats/{chatID}/members/admins", s.api(s.getAdmins))
+
+	mux.HandleFunc("GET /messages", s.api(s.getMessages))
+	mux.HandleFunc("POST /messages", s.api(s.postMessage))
+	mux.HandleFunc("PUT /messages", s.api(s.editMessage))
+	mux.HandleFunc("DELETE /messages", s.api(s.deleteMessage))
+	mux.HandleFunc("GET /messages/{mid}", s.api(s.getMessage))
+	mux.HandleFunc("GET /videos/{token}", s.api(s.getVideo))
+	mux.HandleFunc("POST /answers", s.api(s.postAnswer))
+
+	mux.HandleFunc("GET /updates", s.api(s.getUpdates))
+
+	mux.HandleFunc("GET /subscriptions", s.api(s.getSubscriptions))
+	mux.HandleFunc("POST /subscriptions", s.api(s.subscribe))
+	mux.HandleFunc("DELETE /subscriptions", s.api(s.unsubscribe))
+
+	mux.HandleFunc("POST /uploads", s.api(s.getUploadURL))
+	mux.HandleFunc("POST /upload/{id}", s.upload)
+	mux.HandleFunc("GET /files/{token}", s.download)
+
+	return mux
+}
+
+// api wraps the handler of an API method: checks the token and the injected failures and writes the result as JSON.
+// Handlers lock the server themselves, so long polling can wait for updates without holding the lock.
+func (s *Server) api(handler func(r *http.Request) (any, *apiError)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != s.token {
+			writeError(w, errorf(http.StatusUnauthorized, "verify.token", "Invalid access_token"))
+			return
+		}
+		if err := s.injectedFailure(r); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		result, err := handler(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
+
+func (s *Server) injectedFailure(r *http.Request) *apiError {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, f := range s.failures {
+		if f.method == r.Method && f.path == r.URL.Path {
+			s.failures = slices.Delete(s.failures, i, i+1)
+			return errorf(f.status, f.code, "Injected failure")
+		}
+	}
+
+	return nil
+}
+
+func writeError(w http.ResponseWriter, err *apiError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(err.status)
+	json.NewEncoder(w).Encode(schemes.Error{Code: err.code, Message: err.message})
+}
+
+func decodeBody(r *http.Request, v any) *apiError {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return errorf(http.StatusBadRequest, "proto.payload", "Invalid request body: %v", err)
+	}
+
+	return nil
+}
+
+func queryInt(r *http.Request, name string) (int64, *apiError) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return 0, nil
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, errorf(http.StatusBadRequest, "proto.payload", "Invalid %s: %q", name, value)
+	}
+
+	return n, nil
+}
+
+func success() *schemes.SimpleQueryResult {
+	return &schemes.SimpleQueryResult{Success: true}
+}
+
+func (s *Server) getMe(*http.Request) (any, *apiError) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.bot, nil
+}
+
+func (s *Server) patchMe(r *http.Request) (any, *apiError) {
+	var patch schemes.BotPatch
+	if err := decodeBody(r, &patch); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if patch.Name != "" {
+		s.bot.Name = patch.Name
+	}
+	if patch.Username != "" {
+		s.bot.Username = patch.Username
+	}
+	if patch.Description != "" {
+		s.bot.Description = patch.Description
+	}
+	if patch.Commands != nil {
+		s.bot.Commands = patch.Commands
+	}
+
+	return s.bot, nil
+}
+
+// chat returns the chat from the path, the server must be locked.
+func (s *Server) chat(r *http.Request) (*chat, *apiError) {
+	chatID, err := strconv.ParseInt(r.PathValue("chatID"), 10, 64)
+	if err != nil {
+		return nil, errorf(http.StatusBadRequest, "proto.payload", "Invalid chat_id")
+	}
+
+	c, ok := s.chats[chatID]
+	if !ok {
+		return nil, errorf(http.StatusNotFound, "chat.not.found", "Chat %d not found", chatID)
+	}
+
+	return c, nil
+}
+
+func (s *Server) getChats(r *http.Request) (any, *apiError) {
+	count, err := queryInt(r, "count")
+	if err != nil {
+		return nil, err
+	}
+	marker, err := queryInt(r, "marker")
+	if err != nil {
+		return nil, err
+	}
+	if count <= 0 {
+		count = 50
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := &schemes.ChatList{Chats: []schemes.Chat{}}
+	for i := marker; i < int64(len(s.chatOrder)); i++ {
+		if int64(len(result.Chats)) == count {
+			result.Marker = &i
+			break
+		}
+		result.Chats = append(result.Chats, s.chats[s.chatOrder[i]].Chat)
+	}
+
+	return result, nil
+}
+
+func (s *Server) getChat(r *http.Request) (any, *apiError) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, err := s.chat(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Chat, nil
+}
+
+func (s *Server) patchChat(r *http.Request) (any, *apiError) {
+	var patch schemes.ChatPatch
+	if err := decodeBody(r, &patch); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, err := s.chat(r)
+	if err != nil {
+		return nil, err
+	}
+	if c.Type == schemes.DIALOG {
+		return nil, errorf(http.StatusBadRequest, "chat.not.editable", "Dialog cannot be edited")
+	}
+	if patch.Title != "" {
+		c.Title = patch.Title
+	}
+
+	return c.Chat, nil
+}
+
+func (s *Server) postAction(r *http.Request) (any, *apiError) {
+	var body schemes.ActionRequestBody
+	if err := decodeBody(r, &body); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, err := s.chat(r)
+	if err != nil {
+		return nil, err
+	}
+	s.actions = append(s.actions, Action{ChatID: c.ChatId, Action: body.Action})
+	s.touch()
+
+	return success(), nil
+}
+
+// member returns the member of the chat, the server must be locked.
+func (s *Server) member(c *chat, userID int64) schemes.ChatMember {
+	member := schemes.ChatMember{
+		UserId:   userID,
+		IsOwner:  userID == c.OwnerId,
+		IsAdmin:  slices.Contains(c.admins, userID),
+		JoinTime: c.LastEventTime,
+	}
+	if userID == s.bot.UserId {
+		member.Name, member.Username, member.IsBot = s.bot.Name, s.bot.Username, true
+	} else if user, ok := s.users[userID]; ok {
+		member.Name, member.Username = user.Name, user.Username
+	}
+
+	return member
+}
+
+func (s *Server) getMembers(r *http.Request) (any, *apiError) {
+	count, err := queryInt(r, "count")
+	if err != nil {
+		return nil, err
+	}
+	marker, err := queryInt(r, "marker")
+	if err != nil {
+		return nil, err
+	}
+	if count <= 0 {
+		count = 20
+	}
+
+	var userIDs []int64
+	if ids := r.URL.Query().Get("user_ids"); ids != "" {
+		for _, id := range strings.Split(ids, ",") {
+			userID, parseErr := strconv.ParseInt(id, 10, 64)
+			if parseErr != nil {
+				return nil, errorf(http.StatusBadRequest, "proto.payload", "Invalid user_ids")
+			}
+			userIDs = append(userIDs, userID)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, apiErr := s.chat(r)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	result := &schemes.ChatMembersList{Members: []schemes.ChatMember{}}
+	if userIDs != nil {
+		for _, userID := range userIDs {
+			if slices.Contains(c.members, userID) {
+				result.Members = append(result.Members, s.member(c, userID))
+			}
+		}
+
+		return result, nil
+	}
+
+	for i := marker; i < int64(len(c.members)); i++ {
+		if int64(len(result.Members)) == count {
+			result.Marker = &i
+			break
+		}
+		result.Members = append(result.Members, s.member(c, c.members[i]))
+	}
+
+	return result, nil
+}
+
+func (s *Server) addMembers(r *http.Request) (any, *apiError) {
+	var body schemes.UserIdsList
+	if err := decodeBody(r, &body); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, err := s.chat(r)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range body.UserIds {
+		if userID := int64(id); !slices.Contains(c.members, userID) {
+			c.members = append(c.members, userID)
+		}
+	}
+	c.ParticipantsCount = len(c.members)
+
+	return success(), nil
+}
+
+func (s *Server) removeMember(r *http.Request) (any, *apiError) {
+	userID, err := queryInt(r, "user_id")
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, err := s.chat(r)
+	if err != nil {
+		return nil, err
+	}
+	c.members = slices.DeleteFunc(c.members, func(id int64) bool { return id == userID })
+	c.ParticipantsCount = len(c.members)
+
+	return success(), nil
+}
+
+func (s *Server) getMembership(r *http.Request) (any, *apiError) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, err := s.chat(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.member(c, s.bot.UserId), nil
+}
+
+func (s *Server) leaveChat(r *http.Request) (any, *apiError) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, err := s.chat(r)
+	if err != nil {
+		return nil, err
+	}
+	c.members = slices.DeleteFunc(c.members, func(id int64) bool { return id == s.bot.UserId })
+	c.ParticipantsCount = len(c.members)
+	c.Status = schemes.LEFT
+
+	return success(), nil
+}
+
+func (s *Server) getAdmins(r *http.Request) (any, *apiError) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, err := s.chat(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &schemes.ChatMembersList{Members: []schemes.ChatMember{}}
+	for _, userID := range c.admins {
+		result.Members = append(result.Members, s.member(c, userID))
+	}
+
+	return result, nil
+}
+
+func (s *Server) getMessages(r *http.Request) (any, *apiError) {
+	chatID, err := queryInt(r, "chat_id")
+	if err != nil {
+		return nil, err
+	}
+	count, err := queryInt(r, "count")
+	if err != nil {
+		return nil, err
+	}
+	if count <= 0 {
+		count = 50
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := &schemes.MessageList{Messages: []schemes.Message{}}
+	if ids := r.URL.Query().Get("message_ids"); ids != "" {
+		for _, mid := range strings.Split(ids, ",") {
+			if msg, ok := s.messages[mid]; ok {
+				result.Messages = append(result.Messages, *msg)
+			}
+		}
+
+		return result, nil
+	}
+
+	if _, ok := s.chats[chatID]; !ok {
+		return nil, errorf(http.StatusNotFound, "chat.not.found", "Chat %d not found", chatID)
+	}
+	history := s.history[chatID]
+	for i := len(history) - 1; i >= 0 && int64(len(result.Messages)) < count; i-- {
+		result.Messages = append(result.Messages, *s.messages[history[i]])
+	}
+
+	return result, nil
+}
+
+func (s *Server) getMessage(r *http.Request) (any, *apiError) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msg, ok := s.messages[r.PathValue("mid")]
+	if !ok {
+		return nil, errorf(http.StatusNotFound, "message.not.found", "Message not found")
+	}
+
+	return msg, nil
+}
+
+// newMessageBody is schemes.NewMessageBody with the attachments kept raw, so they can be converted
+// and a missing list can be told from an empty one.
+type newMessageBody struct {
+	Text        string                  `json:"text"`
+	Attachments []json.RawMessage       `json:"attachments"`
+	Link        *schemes.NewMessageLink `json:"link"`
+}
+
+func (s *Server) postMessage(r *http.Request) (any, *apiError) {
+	chatID, err := queryInt(r, "chat_id")
+	if err != nil {
+		return nil, err
+	}
+	userID, err := queryInt(r, "user_id")
+	if err != nil {
+		return nil, err
+	}
+
+	var body newMessageBody
+	if err := decodeBody(r, &body); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.chats[chatID]
+	if userID != 0 {
+		c = s.dialogOf(userID)
+		ok = c != nil
+	}
+	if !ok {
+		return nil, errorf(http.StatusNotFound, "chat.not.found", "Chat not found")
+	}
+	if !slices.Contains(c.members, s.bot.UserId) {
+		return nil, errorf(http.StatusForbidden, "chat.denied", "Bot is not a member of the chat")
+	}
+	if body.Text == "" && len(body.Attachments) == 0 {
+		return nil, errorf(http.StatusBadRequest, "proto.payload", "Message is empty")
+	}
+
+	attachments, apiErr := s.convertAttachments(body.Attachments)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	msg := s.addMessage(s.botUser(), c, body.Text, attachments)
+	s.touch()
+	if body.Link != nil {
+		if linked, ok := s.messages[body.Link.Mid]; ok {
+			msg.Link = &schemes.LinkedMessage{Type: body.Link.Type, Sender: linked.Sender, ChatId: linked.Recipient.ChatId, Message: linked.Body}
+		}
+	}
+
+	return map[string]any{"message": msg}, nil
+}
+
+func (s *Server) editMessage(r *http.Request) (any, *apiError) {
+	var body newMessageBody
+	if err := decodeBody(r, &body); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.edit(r.URL.Query().Get("message_id"), body); err != nil {
+		return nil, err
+	}
+
+	return success(), nil
+}
+
+// edit replaces the text and the attachments of the message sent by the bot. Attachments are kept if the list is missing.
+func (s *Server) edit(mid string, body newMessageBody) *apiError {
+	msg, ok := s.messages[mid]
+	if !ok {
+		return errorf(http.StatusNotFound, "message.not.found", "Message not found")
+	}
+	if msg.Sender.UserId != s.bot.UserId {
+		return errorf(http.StatusForbidden, "message.not.editable", "Message of another user cannot be edited")
+	}
+
+	if body.Attachments != nil {
+		attachments, err := s.convertAttachments(body.Attachments)
+		if err != nil {
+			return err
+		}
+		msg.Body.RawAttachments = attachments
+	}
+	msg.Body.Text = body.Text
+	s.touch()
+
+	return nil
+}
+
+func (s *Server) deleteMessage(r *http.Request) (any, *apiError) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	mid := r.URL.Query().Get("message_id")
+	msg, ok := s.messages[mid]
+	if !ok {
+		return nil, errorf(http.StatusNotFound, "message.not.found", "Message not found")
+	}
+
+	delete(s.messages, mid)
+	chatID := msg.Recipient.ChatId
+	s.history[chatID] = slices.DeleteFunc(s.history[chatID], func(m string) bool { return m == mid })
+	s.touch()
+
+	return success(), nil
+}
+
+func (s *Server) postAnswer(r *http.Request) (any, *apiError) {
+	var body struct {
+		Message      *newMessageBody `json:"message"`
+		Notification string          `json:"notification"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	callbackID := r.URL.Query().Get("callback_id")
+	cb, ok := s.callbacks[callbackID]
+	if !ok {
+		return nil, errorf(http.StatusNotFound, "callback.not.found", "Callback not found")
+	}
+	if cb.answered {
+		return nil, errorf(http.StatusBadRequest, "callback.answered", "Callback has already been answered")
+	}
+
+	answer := Answer{CallbackID: callbackID, CallbackAnswer: schemes.CallbackAnswer{Notification: body.Notification}}
+	if body.Message != nil {
+		if err := s.edit(cb.mid, *body.Message); err != nil {
+			return nil, err
+		}
+		answer.Message = &schemes.NewMessageBody{Text: body.Message.Text}
+		for _, a := range body.Message.Attachments {
+			answer.Message.Attachments = append(answer.Message.Attachments, a)
+		}
+	}
+	cb.answered = true
+	s.answers = append(s.answers, answer)
+	s.touch()
+
+	return success(), nil
+}
+
+func (s *Server) getUpdates(r *http.Request) (any, *apiError) {
+	limit, err := queryInt(r, "limit")
+	if err != nil {
+		return nil, err
+	}
+	timeout, err := queryInt(r, "timeout")
+	if err != nil {
+		return nil, err
+	}
+	marker, err := queryInt(r, "marker")
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+
+	wait := time.NewTimer(max(time.Duration(timeout)*time.Second-pollMargin, 0))
+	defer wait.Stop()
+
+	for {
+		s.mu.Lock()
+		if marker < int64(len(s.updates)) {
+			updates := s.updates[marker:min(marker+limit, int64(len(s.updates)))]
+			next := marker + int64(len(updates))
+			s.mu.Unlock()
+
+			return &schemes.UpdateList{Updates: updates, Marker: &next}, nil
+		}
+		notify := s.notify
+		s.mu.Unlock()
+
+		select {
+		case <-notify:
+		case <-wait.C:
+			return &schemes.UpdateList{Updates: []json.RawMessage{}, Marker: &marker}, nil
+		case <-r.Context().Done():
+			return nil, errorf(http.StatusServiceUnavailable, "request.cancelled", "Request cancelled")
+		case <-s.done:
+			return nil, errorf(http.StatusServiceUnavailable, "service.unavailable", "Server is closed")
+		}
+	}
+}
+
+func (s *Server) getSubscriptions(*http.Request) (any, *apiError) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return &schemes.GetSubscriptionsResult{Subscriptions: append([]schemes.Subscription{}, s.subscriptions...)}, nil
+}
+
+func (s *Server) subscribe(r *http.Request) (any, *apiError) {
+	var body schemes.SubscriptionRequestBody
+	if err := decodeBody(r, &body); err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(body.Url, "http://") && !strings.HasPrefix(body.Url, "https://") {
+		return nil, errorf(http.StatusBadRequest, "proto.payload", "Invalid webhook URL")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.subscriptions = slices.DeleteFunc(s.subscriptions, func(sub schemes.Subscription) bool { return sub.Url == body.Url })
+	s.subscriptions = append(s.subscriptions, schemes.Subscription{
+		Url:         body.Url,
+		Secret:      body.Secret,
+		Time:        time.Now().UnixMilli(),
+		UpdateTypes: body.UpdateTypes,
+		Version:     body.Version,
+	})
+
+	return success(), nil
+}
+
+func (s *Server) unsubscribe(r *http.Request) (any, *apiError) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subscriptionURL := r.URL.Query().Get("url")
+	s.subscriptions = slices.DeleteFunc(s.subscriptions, func(sub schemes.Subscription) bool { return sub.Url == subscriptionURL })
+
+	return success(), nil
+}
+
+func (s *Server) getUploadURL(r *http.Request) (any, *apiError) {
+	uploadType := schemes.UploadType(r.URL.Query().Get("type"))
+	switch uploadType {
+	case schemes.PHOTO, schemes.VIDEO, schemes.AUDIO, schemes.FILE:
+	default:
+		return nil, errorf(http.StatusBadRequest, "proto.payload", "Invalid upload type %q", uploadType)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := strconv.FormatInt(s.newID(), 10)
+	s.uploads[id] = uploadType
+
+	endpoint := &schemes.UploadEndpoint{Url: s.URL + "/upload/" + id}
+	if uploadType == schemes.VIDEO || uploadType == schemes.AUDIO {
+		endpoint.Token = uploadToken(id)
+	}
+
+	return endpoint, nil
+}
+
+func uploadToken(id string) string {
+	return "token-" + id
+}
+
+// upload receives the content of the upload, either as a multipart form or in chunks with Content-Range.
+func (s *Server) upload(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	s.mu.Lock()
+	uploadType, ok := s.uploads[id]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, errorf(http.StatusNotFound, "upload.not.found", "Upload not found"))
+		return
+	}
+
+	name, data, complete, err := readUpload(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	token := uploadToken(id)
+	s.mu.Lock()
+	f, ok := s.files[token]
+	if !ok {
+		f = &file{uploadType: uploadType, name: name}
+		s.files[token] = f
+	}
+	if rangeHeader := r.Header.Get("Content-Range"); rangeHeader != "" {
+		m := contentRangePattern.FindStringSubmatch(rangeHeader)
+		start, _ := strconv.Atoi(m[1])
+		if start != len(f.data) {
+			received := len(f.data)
+			s.mu.Unlock()
+			w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+			fmt.Fprintf(w, "0-%d/%s", received-1, m[3])
+			return
+		}
+	}
+	f.data = append(f.data, data...)
+	received := len(f.data)
+	s.mu.Unlock()
+
+	if !complete {
+		fmt.Fprintf(w, "0-%d/%s", received-1, contentRangePattern.FindStringSubmatch(r.Header.Get("Content-Range"))[3])
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if uploadType == schemes.PHOTO {
+		json.NewEncoder(w).Encode(schemes.PhotoTokens{Photos: map[string]schemes.PhotoToken{id: {Token: token}}})
+		return
+	}
+	json.NewEncoder(w).Encode(schemes.UploadedInfo{Token: token})
+}
+
+// readUpload reads the uploaded content and reports whether the upload is complete.
+func readUpload(r *http.Request) (string, []byte, bool, *apiError) {
+	if rangeHeader := r.Header.Get("Content-Range"); rangeHeader != "" {
+		m := contentRangePattern.FindStringSubmatch(rangeHeader)
+		if m == nil {
+			return "", nil, false, errorf(http.StatusBadRequest, "proto.payload", "Invalid Content-Range")
+		}
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			return "", nil, false, errorf(http.StatusBadRequest, "proto.payload", "Failed to read chunk: %v", err)
+		}
+		_, params, _ := mime.ParseMediaType(r.Header.Get("Content-Disposition"))
+		end, _ := strconv.ParseInt(m[2], 10, 64)
+		total, _ := strconv.ParseInt(m[3], 10, 64)
+
+		return params["filename"], data, end+1 >= total, nil
+	}
+
+	fh, header, err := r.FormFile("data")
+	if err != nil {
+		return "", nil, false, errorf(http.StatusBadRequest, "proto.payload", "Missing file: %v", err)
+	}
+	defer fh.Close()
+
+	data, err := io.ReadAll(fh)
+	if err != nil {
+		return "", nil, false, errorf(http.StatusBadRequest, "proto.payload", "Failed to read file: %v", err)
+	}
+
+	return header.Filename, data, true, nil
+}
+
+// download serves the uploaded content by the URLs of the attachments.
+func (s *Server) download(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	f, ok := s.files[r.PathValue("token")]
+	s.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", http.DetectContentType(f.data))
+	http.ServeContent(w, r, f.name, time.Time{}, bytes.NewReader(f.data))
+}
+
+func (s *Server) getVideo(r *http.Request) (any, *apiError) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token := r.PathValue("token")
+	f, ok := s.files[token]
+	if !ok || f.uploadType != schemes.VIDEO {
+		return nil, errorf(http.StatusNotFound, "video.not.found", "Video not found")
+	}
+
+	return &schemes.VideoAttachmentDetails{
+		Token: token,
+		Urls:  &schemes.VideoUrls{Mp4720: s.fileURL(token)},
+	}, nil
+}
+
+func (s *Server) fileURL(token string) string {
+	return s.URL + "/files/" + token
+}
+
+// convertAttachments turns the attachment requests into the attachments of a message, as the platform does:
+// uploaded media get URLs, other attachments are kept as is. The server must be locked.
+func (s *Server) convertAttachments(requests []json.RawMessage) ([]json.RawMessage, *apiError) {
+	attachments := make([]json.RawMessage, 0, len(requests))
+	for _, raw := range requests {
+		var request struct {
+			Type    schemes.AttachmentType `json:"type"`
+			Payload struct {
+				Token  string                        `json:"token"`
+				Url    string                        `json:"url"`
+				Photos map[string]schemes.PhotoToken `json:"photos"`
+			} `json:"payload"`
+		}
+		if err := json.Unmarshal(raw, &request); err != nil {
+			return nil, errorf(http.StatusBadRequest, "proto.payload", "Invalid attachment: %v", err)
+		}
+
+		var attachment any
+		switch request.Type {
+		case schemes.AttachmentImage:
+			token := request.Payload.Token
+			for _, photo := range request.Payload.Photos {
+				token = photo.Token
+			}
+			url := request.Payload.Url
+			if token != "" {
+				url = s.fileURL(token)
+			}
+			attachment = &schemes.PhotoAttachment{
+				Attachment: schemes.Attachment{Type: request.Type},
+				Payload:    schemes.PhotoAttachmentPayload{PhotoId: s.newID(), Token: token, Url: url},
+			}
+		case schemes.AttachmentVideo, schemes.AttachmentAudio, schemes.AttachmentFile:
+			f, ok := s.files[request.Payload.Token]
+			if !ok {
+				return nil, errorf(http.StatusBadRequest, "attachment.not.found", "Attachment %q not found", request.Payload.Token)
+			}
+			payload := schemes.MediaAttachmentPayload{Url: s.fileURL(request.Payload.Token), Token: request.Payload.Token}
+			switch request.Type {
+			case schemes.AttachmentVideo:
+				attachment = &schemes.VideoAttachment{Attachment: schemes.Attachment{Type: request.Type}, Payload: payload}
+			case schemes.AttachmentAudio:
+				attachment = &schemes.AudioAttachment{Attachment: schemes.Attachment{Type: request.Type}, Payload: payload}
+			default:
+				attachment = &schemes.FileAttachment{
+					Attachment: schemes.Attachment{Type: request.Type},
+					Payload:    schemes.FileAttachmentPayload{Url: payload.Url, Token: payload.Token},
+					Filename:   f.name,
+					Size:       int64(len(f.data)),
+				}
+			}
+		default:
+			attachments = append(attachments, raw)
+			continue
+		}
+
+		data, err := json.Marshal(attachment)
+		if err != nil {
+			return nil, errorf(http.StatusInternalServerError, "internal.error", "Failed to encode attachment: %v", err)
+		}
+		attachments = append(attachments, data)
+	}
+
+	return attachments, nil
+}
@@ -0,0 +1,482 @@
+// Package maxtest provides an in-process fake MAX Bot API server for integration tests of bots.
+//
+// The server keeps users, chats, message history, uploaded files and webhook subscriptions in memory
+// and implements the endpoints used by the maxbot client, so a client created by Server.Client
+// behaves as if it talks to the platform. Tests drive the conversation from the users' side
+// with PostText, PressButton and StartBot and inspect what the bot has done with Messages, Answers and Actions.
+package maxtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pavmos/max-bot-api-client-go"
+	"github.com/pavmos/max-bot-api-client-go/configservice"
+	"github.com/pavmos/max-bot-api-client-go/schemes"
+)
+
+const (
+	// DefaultToken is the bot token accepted by the server.
+	DefaultToken = "maxtest-token"
+
+	// clientTimeout is the timeout of clients created by Server.Client, long polling waits a bit less.
+	clientTimeout = 5
+	pollMargin    = 500 * time.Millisecond
+)
+
+// Answer is the answer of the bot on a callback.
+type Answer struct {
+	CallbackID string
+	schemes.CallbackAnswer
+}
+
+// Action is the action sent by the bot to a chat, e.g. typing_on.
+type Action struct {
+	ChatID int64
+	Action schemes.SenderAction
+}
+
+type chat struct {
+	schemes.Chat
+	members []int64
+	admins  []int64
+	dialog  int64 // User of the dialog
+}
+
+type callback struct {
+	mid      string
+	answered bool
+}
+
+type file struct {
+	uploadType schemes.UploadType
+	name       string
+	data       []byte
+}
+
+type failure struct {
+	method, path string
+	status       int
+	code         string
+}
+
+// Server is a fake MAX Bot API server. It is safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	t     testing.TB
+	token string
+
+	mu            sync.Mutex
+	nextID        int64
+	bot           schemes.BotInfo
+	users         map[int64]schemes.User
+	chats         map[int64]*chat
+	chatOrder     []int64
+	messages      map[string]*schemes.Message
+	history       map[int64][]string
+	callbacks     map[string]*callback
+	answers       []Answer
+	actions       []Action
+	subscriptions []schemes.Subscription
+	uploads       map[string]schemes.UploadType
+	files         map[string]*file
+	failures      []failure
+
+	updates  []json.RawMessage
+	notify   chan struct{} // Closed and replaced when updates are added
+	changed  chan struct{} // Closed and replaced when the bot changes something
+	done     chan struct{}
+	doneOnce sync.Once
+}
+
+// NewServer starts a fake server with the bot "maxtest_bot" and closes it when the test ends.
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+
+	s := &Server{
+		t:         t,
+		token:     DefaultToken,
+		nextID:    100,
+		users:     make(map[int64]schemes.User),
+		chats:     make(map[int64]*chat),
+		messages:  make(map[string]*schemes.Message),
+		history:   make(map[int64][]string),
+		callbacks: make(map[string]*callback),
+		uploads:   make(map[string]schemes.UploadType),
+		files:     make(map[string]*file),
+		notify:    make(chan struct{}),
+		changed:   make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	s.bot = schemes.BotInfo{UserId: s.newID(), Name: "Test bot", Username: "maxtest_bot"}
+	s.Server = httptest.NewServer(s.routes())
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// Close stops the server, interrupting pending long polling requests.
+func (s *Server) Close() {
+	s.doneOnce.Do(func() { close(s.done) })
+	s.Server.Close()
+}
+
+// Config returns the configuration pointing maxbot.NewWithConfig at the server.
+func (s *Server) Config() configservice.ConfigInterface {
+	return &config{url: s.URL + "/", token: s.token}
+}
+
+// Client returns a client of the server.
+func (s *Server) Client(t testing.TB) *maxbot.Api {
+	t.Helper()
+
+	api, err := maxbot.NewWithConfig(s.Config())
+	if err != nil {
+		t.Fatalf("maxtest: create client: %v", err)
+	}
+
+	return api
+}
+
+// Bot returns the bot served by the server.
+func (s *Server) Bot() schemes.BotInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.bot
+}
+
+// AddUser registers a user and opens the dialog of the user with the bot.
+func (s *Server) AddUser(name string) schemes.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user := schemes.User{UserId: s.newID(), Name: name, FirstName: name}
+	s.users[user.UserId] = user
+
+	c := s.addChat(schemes.DIALOG, "", user.UserId)
+	c.dialog = user.UserId
+
+	return user
+}
+
+// AddChat creates a group chat with the bot and the members. The first member is the owner and the admin.
+func (s *Server) AddChat(title string, members ...schemes.User) schemes.Chat {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make([]int64, len(members))
+	for i, m := range members {
+		ids[i] = m.UserId
+	}
+	c := s.addChat(schemes.CHAT, title, ids...)
+	if len(ids) > 0 {
+		c.OwnerId = ids[0]
+		c.admins = ids[:1]
+	}
+
+	return c.Chat
+}
+
+// Dialog returns the identifier of the dialog of the user with the bot.
+func (s *Server) Dialog(user schemes.User) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c := s.dialogOf(user.UserId); c != nil {
+		return c.ChatId
+	}
+
+	return 0
+}
+
+// Chat returns the chat by its identifier.
+func (s *Server) Chat(chatID int64) (schemes.Chat, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.chats[chatID]
+	if !ok {
+		return schemes.Chat{}, false
+	}
+
+	return c.Chat, true
+}
+
+// PostText posts the text message of the user to the chat and delivers the message_created update to the bot.
+// The chat must exist, otherwise the test fails.
+func (s *Server) PostText(from schemes.User, chatID int64, text string) schemes.Message {
+	s.t.Helper()
+
+	return s.post(s.t, from, chatID, text, nil)
+}
+
+// post fails the test t of the caller, e.g. of a User, if the chat does not exist.
+func (s *Server) post(t testing.TB, from schemes.User, chatID int64, text string, attachments []json.RawMessage) schemes.Message {
+	t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.chats[chatID]
+	if !ok {
+		t.Fatalf("maxtest: post to unknown chat %d", chatID)
+	}
+	msg := s.addMessage(from, c, text, attachments)
+	s.pushUpdate(&schemes.MessageCreatedUpdate{
+		Update:  schemes.Update{UpdateType: schemes.TypeMessageCreated, Timestamp: int(time.Now().Unix())},
+		Message: *msg,
+	})
+
+	return *msg
+}
+
+// PressButton presses the callback button with the payload under the message and delivers the message_callback update.
+// It returns the identifier of the callback.
+func (s *Server) PressButton(from schemes.User, mid string, payload string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	callbackID := fmt.Sprintf("cb.%d", s.newID())
+	s.callbacks[callbackID] = &callback{mid: mid}
+
+	update := &schemes.MessageCallbackUpdate{
+		Update: schemes.Update{UpdateType: schemes.TypeMessageCallback, Timestamp: int(time.Now().Unix())},
+		Callback: schemes.Callback{
+			Timestamp:  time.Now().UnixMilli(),
+			CallbackID: callbackID,
+			Payload:    payload,
+			User:       from,
+		},
+	}
+	if msg, ok := s.messages[mid]; ok {
+		update.Message = msg
+	}
+	s.pushUpdate(update)
+
+	return callbackID
+}
+
+// StartBot presses the Start button in the dialog of the user with the bot and delivers the bot_started update.
+func (s *Server) StartBot(user schemes.User, payload string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var chatID int64
+	if c := s.dialogOf(user.UserId); c != nil {
+		chatID = c.ChatId
+	}
+	s.pushUpdate(&schemes.BotStartedUpdate{
+		Update:  schemes.Update{UpdateType: schemes.TypeBotStarted, Timestamp: int(time.Now().Unix())},
+		ChatId:  chatID,
+		User:    user,
+		Payload: payload,
+	})
+}
+
+// PushUpdate delivers an arbitrary update to the bot.
+func (s *Server) PushUpdate(update schemes.UpdateInterface) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pushUpdate(update)
+}
+
+// Messages returns the history of the chat, oldest first.
+func (s *Server) Messages(chatID int64) []schemes.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result []schemes.Message
+	for _, mid := range s.history[chatID] {
+		result = append(result, *s.messages[mid])
+	}
+
+	return result
+}
+
+// Message returns the message by its identifier.
+func (s *Server) Message(mid string) (schemes.Message, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msg, ok := s.messages[mid]
+	if !ok {
+		return schemes.Message{}, false
+	}
+
+	return *msg, true
+}
+
+// Answers returns the answers of the bot on callbacks.
+func (s *Server) Answers() []Answer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.answers)
+}
+
+// Actions returns the actions sent by the bot.
+func (s *Server) Actions() []Action {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.actions)
+}
+
+// Subscriptions returns the webhook subscriptions of the bot.
+func (s *Server) Subscriptions() []schemes.Subscription {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.subscriptions)
+}
+
+// File returns the content uploaded with the token.
+func (s *Server) File(token string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, ok := s.files[token]
+	if !ok {
+		return nil, false
+	}
+
+	return f.data, true
+}
+
+// FailNext makes the next request to the method and the path, e.g. "POST /messages", fail with the status and the error code.
+func (s *Server) FailNext(method, path string, status int, code string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures = append(s.failures, failure{method: method, path: path, status: status, code: code})
+}
+
+func (s *Server) newID() int64 {
+	s.nextID++
+
+	return s.nextID
+}
+
+func (s *Server) addChat(chatType schemes.ChatType, title string, members ...int64) *chat {
+	c := &chat{
+		Chat: schemes.Chat{
+			ChatId:            s.newID(),
+			Type:              chatType,
+			Status:            schemes.ACTIVE,
+			Title:             title,
+			LastEventTime:     int(time.Now().UnixMilli()),
+			ParticipantsCount: len(members) + 1,
+		},
+		members: append([]int64{s.bot.UserId}, members...),
+	}
+	s.chats[c.ChatId] = c
+	s.chatOrder = append(s.chatOrder, c.ChatId)
+
+	return c
+}
+
+func (s *Server) dialogOf(userID int64) *chat {
+	for _, id := range s.chatOrder {
+		if c := s.chats[id]; c.dialog == userID {
+			return c
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) addMessage(from schemes.User, c *chat, text string, attachments []json.RawMessage) *schemes.Message {
+	if attachments == nil {
+		attachments = []json.RawMessage{}
+	}
+
+	msg := &schemes.Message{
+		Sender:    from,
+		Recipient: schemes.Recipient{ChatId: c.ChatId, ChatType: c.Type},
+		Timestamp: time.Now().UnixMilli(),
+		Body: schemes.MessageBody{
+			Mid:            fmt.Sprintf("mid.%016x", s.newID()),
+			Seq:            int64(len(s.history[c.ChatId]) + 1),
+			Text:           text,
+			RawAttachments: attachments,
+		},
+	}
+	if c.Type == schemes.DIALOG {
+		msg.Recipient.UserId = c.dialog
+		if from.UserId == c.dialog {
+			msg.Recipient.UserId = s.bot.UserId
+		}
+	}
+
+	s.messages[msg.Body.Mid] = msg
+	s.history[c.ChatId] = append(s.history[c.ChatId], msg.Body.Mid)
+	c.LastEventTime = int(msg.Timestamp)
+
+	return msg
+}
+
+func (s *Server) pushUpdate(update any) {
+	data, err := json.Marshal(update)
+	if err != nil {
+		panic(fmt.Sprintf("maxtest: marshal update: %v", err))
+	}
+
+	s.updates = append(s.updates, data)
+	close(s.notify)
+	s.notify = make(chan struct{})
+}
+
+// touch wakes up the waiters for the actions of the bot, the server must be locked.
+func (s *Server) touch() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// wait waits until cond, checked under the lock, holds after the actions of the bot or the timeout expires.
+func (s *Server) wait(timeout time.Duration, cond func() bool) bool {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		s.mu.Lock()
+		ok := cond()
+		changed := s.changed
+		s.mu.Unlock()
+		if ok {
+			return true
+		}
+
+		select {
+		case <-changed:
+		case <-timer.C:
+			return false
+		case <-s.done:
+			return false
+		}
+	}
+}
+
+func (s *Server) botUser() schemes.User {
+	return schemes.User{UserId: s.bot.UserId, Name: s.bot.Name, Username: s.bot.Username, IsBot: true}
+}
+
+// config points the client at the server.
+type config struct {
+	url   string
+	token string
+}
+
+func (c *config) GetHttpBotAPIUrl() string        { return c.url }
+func (c *config) GetHttpBotAPITimeOut() int       { return clientTimeout }
+func (c *config) GetHttpBotAPIVersion() string    { return "" }
+func (c *config) BotTokenCheckInInputSteam() bool { return false }
+func (c *config) BotTokenCheckString() string     { return c.token }
+func (c *config) GetDebugLogMode() bool           { return false }
+func (c *config) GetDebugLogChat() int64          { return 0 }
@@ -0,0 +1,199 @@
+package maxtest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavmos/max-bot-api-client-go"
+	"github.com/pavmos/max-bot-api-client-go/schemes"
+)
+
+func nextUpdate(t *testing.T, updates <-chan schemes.UpdateInterface) schemes.UpdateInterface {
+	t.Helper()
+
+	select {
+	case upd := <-updates:
+		return upd
+	case <-time.After(5 * time.Second):
+		t.Fatal("no update received")
+		return nil
+	}
+}
+
+func TestServer_Conversation(t *testing.T) {
+	server := NewServer(t)
+	api := server.Client(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bot, err := api.Bots.GetBot(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "maxtest_bot", bot.Username)
+
+	alice := server.AddUser("Alice")
+	dialog := server.Dialog(alice)
+	updates := api.GetUpdates(ctx)
+
+	server.PostText(alice, dialog, "hello")
+	created, ok := nextUpdate(t, updates).(*schemes.MessageCreatedUpdate)
+	require.True(t, ok)
+	require.Equal(t, "hello", created.Message.Body.Text)
+	require.Equal(t, alice.UserId, created.Message.Sender.UserId)
+
+	keyboard := maxbot.NewKeyboardBuilder()
+	keyboard.AddRow().AddCallback("Like", schemes.POSITIVE, "like")
+	sent, err := api.Messages.SendWithResult(ctx, maxbot.NewMessage().SetUser(alice.UserId).SetText("pick").AddKeyboard(keyboard))
+	require.NoError(t, err)
+	require.Equal(t, dialog, sent.Recipient.ChatId)
+
+	server.PressButton(alice, sent.Body.Mid, "like")
+	pressed, ok := nextUpdate(t, updates).(*schemes.MessageCallbackUpdate)
+	require.True(t, ok)
+	require.Equal(t, "like", pressed.Callback.Payload)
+	require.Len(t, pressed.Message.Body.Attachments, 1, "callback must carry the original message with the keyboard")
+
+	require.NoError(t, maxbot.NewCallbackReply(api.Messages, pressed).EditText(ctx, "liked"))
+	require.Len(t, server.Answers(), 1)
+
+	history := server.Messages(dialog)
+	require.Len(t, history, 2)
+	require.Equal(t, "liked", history[1].Body.Text)
+	require.Len(t, history[1].Body.RawAttachments, 1, "the keyboard must be kept")
+}
+
+func TestServer_Attachments(t *testing.T) {
+	server := NewServer(t)
+	api := server.Client(t)
+	ctx := context.Background()
+	alice := server.AddUser("Alice")
+
+	content := []byte("%PDF-1.7\nreport")
+	doc, err := api.Uploads.UploadMediaFromReaderWithName(ctx, schemes.FILE, bytes.NewReader(content), "report.pdf")
+	require.NoError(t, err)
+	stored, ok := server.File(doc.Token)
+	require.True(t, ok)
+	require.Equal(t, content, stored)
+
+	sent, err := api.Messages.SendWithResult(ctx, maxbot.NewMessage().SetUser(alice.UserId).AddFile(doc))
+	require.NoError(t, err)
+	attachments, err := api.ConvertRawAttachments(sent.Body.RawAttachments)
+	require.NoError(t, err)
+	require.Len(t, attachments, 1)
+	file, ok := attachments[0].(*schemes.FileAttachment)
+	require.True(t, ok)
+	require.Equal(t, "report.pdf", file.Filename)
+
+	var downloaded bytes.Buffer
+	_, err = api.Download(ctx, file, &downloaded)
+	require.NoError(t, err)
+	require.Equal(t, content, downloaded.Bytes())
+
+	err = api.Messages.Send(ctx, maxbot.NewMessage().SetUser(alice.UserId).AddFile(&schemes.UploadedInfo{Token: "unknown"}))
+	require.ErrorIs(t, err, &maxbot.APIError{Code: http.StatusBadRequest})
+}
+
+func TestServer_Chats(t *testing.T) {
+	server := NewServer(t)
+	api := server.Client(t)
+	ctx := context.Background()
+
+	alice, bob := server.AddUser("Alice"), server.AddUser("Bob")
+	group := server.AddChat("Team", alice, bob)
+
+	chats, err := api.Chats.GetChats(ctx, 2, 0)
+	require.NoError(t, err)
+	require.Len(t, chats.Chats, 2)
+	require.NotNil(t, chats.Marker)
+
+	members, err := api.Chats.GetChatMembers(ctx, group.ChatId, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, members.Members, 3)
+
+	admins, err := api.Chats.GetChatAdmins(ctx, group.ChatId)
+	require.NoError(t, err)
+	require.Equal(t, alice.UserId, admins.Members[0].UserId)
+
+	chat, err := api.Chats.EditChat(ctx, group.ChatId, &schemes.ChatPatch{Title: "Core team"})
+	require.NoError(t, err)
+	require.Equal(t, "Core team", chat.Title)
+
+	_, err = api.Chats.SendAction(ctx, group.ChatId, schemes.TYPING_ON)
+	require.NoError(t, err)
+	require.Equal(t, []Action{{ChatID: group.ChatId, Action: schemes.TYPING_ON}}, server.Actions())
+
+	_, err = api.Chats.LeaveChat(ctx, group.ChatId)
+	require.NoError(t, err)
+	err = api.Messages.Send(ctx, maxbot.NewMessage().SetChat(group.ChatId).SetText("still here?"))
+	require.ErrorIs(t, err, &maxbot.APIError{Code: http.StatusForbidden})
+}
+
+func TestServer_Subscriptions(t *testing.T) {
+	server := NewServer(t)
+	api := server.Client(t)
+	ctx := context.Background()
+
+	_, err := api.Subscriptions.Subscribe(ctx, "https://bot.example.com/hook", []string{"message_created"}, "secret")
+	require.NoError(t, err)
+	result, err := api.Subscriptions.GetSubscriptions(ctx)
+	require.NoError(t, err)
+	require.Len(t, result.Subscriptions, 1)
+	require.Equal(t, "https://bot.example.com/hook", result.Subscriptions[0].Url)
+
+	_, err = api.Subscriptions.Unsubscribe(ctx, "https://bot.example.com/hook")
+	require.NoError(t, err)
+	require.Empty(t, server.Subscriptions())
+}
+
+func TestServer_Errors(t *testing.T) {
+	server := NewServer(t)
+	ctx := context.Background()
+
+	api, err := maxbot.NewWithConfig(&config{url: server.URL + "/", token: "wrong"})
+	require.NoError(t, err)
+	_, err = api.Bots.GetBot(ctx)
+	require.ErrorIs(t, err, &maxbot.APIError{Code: http.StatusUnauthorized})
+
+	api = server.Client(t)
+	server.FailNext(http.MethodGet, "/me", http.StatusServiceUnavailable, "service.unavailable")
+	_, err = api.Bots.GetBot(ctx)
+	require.ErrorIs(t, err, &maxbot.APIError{Code: http.StatusServiceUnavailable})
+	_, err = api.Bots.GetBot(ctx)
+	require.NoError(t, err, "failure must be injected once")
+}
+
+// fatalRecorder records the failure instead of failing the test.
+type fatalRecorder struct {
+	testing.TB
+	fatal string
+}
+
+func (r *fatalRecorder) Fatalf(format string, args ...any) {
+	r.fatal = fmt.Sprintf(format, args...)
+	runtime.Goexit()
+}
+
+func TestServer_PostToUnknownChat(t *testing.T) {
+	tb := &fatalRecorder{TB: t}
+	server := NewServer(tb)
+	alice := server.AddUser("Alice")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		server.PostText(alice, 12345, "hello")
+	}()
+	<-done
+
+	require.Equal(t, "maxtest: post to unknown chat 12345", tb.fatal)
+
+	// The server is not left locked.
+	_, ok := server.Chat(12345)
+	require.False(t, ok)
+}
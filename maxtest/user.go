@@ -0,0 +1,367 @@
+package maxtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pavmos/max-bot-api-client-go"
+	"github.com/pavmos/max-bot-api-client-go/schemes"
+)
+
+// DefaultWaitTimeout is how long User and Callback wait for the bot to react.
+const DefaultWaitTimeout = 5 * time.Second
+
+// silencePeriod is how long ExpectSilence waits for unexpected messages.
+const silencePeriod = 200 * time.Millisecond
+
+// User is a simulated user talking to the bot in a chat. It drives the conversation and asserts on the reaction
+// of the bot, failing the test with a descriptive message when the bot does not behave as expected:
+//
+//	alice := server.NewUser(t, "Alice")
+//	alice.Send("/start").ExpectReply().HasText("Hi, Alice!").HasButtons("Yes", "No")
+//	alice.Press("Yes").ExpectEdit().HasText("Done")
+type User struct {
+	schemes.User
+
+	// Timeout limits waiting for the bot, DefaultWaitTimeout by default.
+	Timeout time.Duration
+
+	t      testing.TB
+	server *Server
+	chatID int64
+	seen   map[string]bool // Messages of the bot already returned by ExpectReply
+}
+
+// NewUser registers a user talking to the bot in their dialog.
+func (s *Server) NewUser(t testing.TB, name string) *User {
+	t.Helper()
+
+	user := s.AddUser(name)
+
+	return s.userIn(t, user, s.Dialog(user))
+}
+
+func (s *Server) userIn(t testing.TB, user schemes.User, chatID int64) *User {
+	u := &User{
+		User:    user,
+		Timeout: DefaultWaitTimeout,
+		t:       t,
+		server:  s,
+		chatID:  chatID,
+		seen:    make(map[string]bool),
+	}
+	for _, msg := range s.Messages(chatID) {
+		u.seen[msg.Body.Mid] = true
+	}
+
+	return u
+}
+
+// In returns the same user talking in the chat, e.g. a group created by Server.AddChat.
+func (u *User) In(chat schemes.Chat) *User {
+	u.t.Helper()
+
+	return u.server.userIn(u.t, u.User, chat.ChatId)
+}
+
+// ChatID returns the chat the user talks in.
+func (u *User) ChatID() int64 {
+	return u.chatID
+}
+
+// Send sends the text message to the bot.
+func (u *User) Send(text string) *User {
+	u.t.Helper()
+
+	u.server.post(u.t, u.User, u.chatID, text, nil)
+
+	return u
+}
+
+// ShareContact sends the contact card.
+func (u *User) ShareContact(name, phone string) *User {
+	u.t.Helper()
+
+	u.sendAttachment(&schemes.ContactAttachment{
+		Attachment: schemes.Attachment{Type: schemes.AttachmentContact},
+		Payload: schemes.ContactAttachmentPayload{
+			VcfInfo: "BEGIN:VCARD\r\nVERSION:3.0\r\nFN:" + name + "\r\nTEL:" + phone + "\r\nEND:VCARD\r\n",
+			TamInfo: &schemes.User{UserId: u.UserId, Name: name},
+		},
+	})
+
+	return u
+}
+
+// ShareLocation sends the location.
+func (u *User) ShareLocation(latitude, longitude float64) *User {
+	u.t.Helper()
+
+	u.sendAttachment(&schemes.LocationAttachment{
+		Attachment: schemes.Attachment{Type: schemes.AttachmentLocation},
+		Latitude:   latitude,
+		Longitude:  longitude,
+	})
+
+	return u
+}
+
+func (u *User) sendAttachment(attachment any) {
+	u.t.Helper()
+
+	data, err := json.Marshal(attachment)
+	if err != nil {
+		u.t.Fatalf("maxtest: marshal attachment: %v", err)
+	}
+	u.server.post(u.t, u.User, u.chatID, "", []json.RawMessage{data})
+}
+
+// Press presses the callback button with the label under the latest message of the bot that has it.
+func (u *User) Press(label string) *Callback {
+	u.t.Helper()
+
+	messages := u.server.Messages(u.chatID)
+	for i := len(messages) - 1; i >= 0; i-- {
+		msg := messages[i]
+		if msg.Sender.UserId != u.server.Bot().UserId {
+			continue
+		}
+
+		reply := &Reply{Message: msg, t: u.t}
+		for _, button := range reply.buttons() {
+			if button.GetText() != label {
+				continue
+			}
+			callbackButton, ok := button.(schemes.CallbackButton)
+			if !ok {
+				u.t.Fatalf("maxtest: button %q is a %s button, not a callback", label, button.GetType())
+			}
+
+			return &Callback{
+				ID:       u.server.PressButton(u.User, msg.Body.Mid, callbackButton.Payload),
+				user:     u,
+				mid:      msg.Body.Mid,
+				snapshot: bodySnapshot(msg),
+			}
+		}
+	}
+
+	u.t.Fatalf("maxtest: no button %q under the messages of the bot in chat %d", label, u.chatID)
+	return nil
+}
+
+// ExpectReply waits for the next message of the bot in the chat.
+func (u *User) ExpectReply() *Reply {
+	u.t.Helper()
+
+	var reply *schemes.Message
+	ok := u.server.wait(u.Timeout, func() bool {
+		reply = u.server.nextBotMessage(u.chatID, u.seen)
+		return reply != nil
+	})
+	if !ok {
+		u.t.Fatalf("maxtest: no reply of the bot to %s in %v", u.Name, u.Timeout)
+		return nil
+	}
+	u.seen[reply.Body.Mid] = true
+
+	return &Reply{Message: *reply, t: u.t}
+}
+
+// ExpectSilence checks that the bot does not send anything else to the chat for a short while.
+func (u *User) ExpectSilence() {
+	u.t.Helper()
+
+	var reply *schemes.Message
+	if u.server.wait(silencePeriod, func() bool {
+		reply = u.server.nextBotMessage(u.chatID, u.seen)
+		return reply != nil
+	}) {
+		u.t.Fatalf("maxtest: unexpected message of the bot to %s: %q", u.Name, reply.Body.Text)
+	}
+}
+
+// nextBotMessage returns the oldest message of the bot in the chat not seen yet, the server must be locked.
+func (s *Server) nextBotMessage(chatID int64, seen map[string]bool) *schemes.Message {
+	for _, mid := range s.history[chatID] {
+		if msg := s.messages[mid]; msg.Sender.UserId == s.bot.UserId && !seen[mid] {
+			return msg
+		}
+	}
+
+	return nil
+}
+
+// Callback is a press of a callback button.
+type Callback struct {
+	ID string
+
+	user     *User
+	mid      string
+	snapshot []byte
+}
+
+// ExpectAnswer waits for the bot to answer the callback.
+func (c *Callback) ExpectAnswer() Answer {
+	c.user.t.Helper()
+
+	var answer Answer
+	ok := c.user.server.wait(c.user.Timeout, func() bool {
+		i := slices.IndexFunc(c.user.server.answers, func(a Answer) bool { return a.CallbackID == c.ID })
+		if i >= 0 {
+			answer = c.user.server.answers[i]
+		}
+		return i >= 0
+	})
+	if !ok {
+		c.user.t.Fatalf("maxtest: callback %s has not been answered in %v", c.ID, c.user.Timeout)
+	}
+
+	return answer
+}
+
+// ExpectNotification waits for the answer to the callback and checks its notification.
+func (c *Callback) ExpectNotification(text string) {
+	c.user.t.Helper()
+
+	if answer := c.ExpectAnswer(); answer.Notification != text {
+		c.user.t.Fatalf("maxtest: callback notification is %q, want %q", answer.Notification, text)
+	}
+}
+
+// ExpectEdit waits for the bot to change the message with the pressed button, by the answer or by editing it later.
+func (c *Callback) ExpectEdit() *Reply {
+	c.user.t.Helper()
+
+	var msg schemes.Message
+	ok := c.user.server.wait(c.user.Timeout, func() bool {
+		current, exists := c.user.server.messages[c.mid]
+		if exists {
+			msg = *current
+		}
+		return exists && !bytes.Equal(bodySnapshot(msg), c.snapshot)
+	})
+	if !ok {
+		c.user.t.Fatalf("maxtest: message %s has not been edited in %v", c.mid, c.user.Timeout)
+		return nil
+	}
+
+	return &Reply{Message: msg, t: c.user.t}
+}
+
+func bodySnapshot(msg schemes.Message) []byte {
+	data, _ := json.Marshal(msg.Body)
+
+	return data
+}
+
+// Reply is a message of the bot with assertions on its content.
+type Reply struct {
+	schemes.Message
+
+	t testing.TB
+}
+
+// HasText checks the text of the message.
+func (r *Reply) HasText(want string) *Reply {
+	r.t.Helper()
+
+	if r.Body.Text != want {
+		r.t.Fatalf("maxtest: reply text is %q, want %q", r.Body.Text, want)
+	}
+
+	return r
+}
+
+// ContainsText checks that the text of the message contains the substring.
+func (r *Reply) ContainsText(substr string) *Reply {
+	r.t.Helper()
+
+	if !strings.Contains(r.Body.Text, substr) {
+		r.t.Fatalf("maxtest: reply text %q does not contain %q", r.Body.Text, substr)
+	}
+
+	return r
+}
+
+// HasButtons checks the labels of all buttons of the inline keyboard, row by row.
+func (r *Reply) HasButtons(labels ...string) *Reply {
+	r.t.Helper()
+
+	if got := r.labels(); !slices.Equal(got, labels) {
+		r.t.Fatalf("maxtest: reply buttons are %q, want %q", got, labels)
+	}
+
+	return r
+}
+
+// HasNoKeyboard checks that the message has no inline keyboard.
+func (r *Reply) HasNoKeyboard() *Reply {
+	r.t.Helper()
+
+	if r.Keyboard() != nil {
+		r.t.Fatalf("maxtest: reply has a keyboard with buttons %q", r.labels())
+	}
+
+	return r
+}
+
+// Keyboard returns the inline keyboard of the message or nil.
+func (r *Reply) Keyboard() *schemes.Keyboard {
+	for _, attachment := range r.Attachments() {
+		if keyboard, ok := attachment.(*schemes.InlineKeyboardAttachment); ok {
+			return &keyboard.Payload
+		}
+	}
+
+	return nil
+}
+
+// Attachments returns the attachments of the message decoded into schemes types, e.g. *schemes.PhotoAttachment.
+func (r *Reply) Attachments() []any {
+	r.t.Helper()
+
+	attachments, err := decodeAttachments(r.Body.RawAttachments)
+	if err != nil {
+		r.t.Fatalf("maxtest: decode attachments: %v", err)
+	}
+
+	return attachments
+}
+
+func (r *Reply) buttons() []schemes.ButtonInterface {
+	keyboard := r.Keyboard()
+	if keyboard == nil {
+		return nil
+	}
+
+	var buttons []schemes.ButtonInterface
+	for _, row := range keyboard.Buttons {
+		buttons = append(buttons, row...)
+	}
+
+	return buttons
+}
+
+func (r *Reply) labels() []string {
+	var labels []string
+	for _, button := range r.buttons() {
+		labels = append(labels, button.GetText())
+	}
+
+	return labels
+}
+
+// decodeAttachments decodes the attachments the way the client does.
+func decodeAttachments(raw []json.RawMessage) ([]any, error) {
+	api, err := maxbot.New(DefaultToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return api.ConvertRawAttachments(raw)
+}
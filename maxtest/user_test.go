@@ -0,0 +1,79 @@
+package maxtest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/pavmos/max-bot-api-client-go"
+	"github.com/pavmos/max-bot-api-client-go/schemes"
+)
+
+// runBot runs a small bot: it greets on /start with a confirmation keyboard and reacts to contacts and locations.
+func runBot(ctx context.Context, api *maxbot.Api) {
+	for upd := range api.GetUpdates(ctx) {
+		switch u := upd.(type) {
+		case *schemes.MessageCreatedUpdate:
+			reply := maxbot.NewMessage().SetChat(u.Message.Recipient.ChatId)
+			switch {
+			case u.Message.Body.Text == "/start":
+				keyboard := maxbot.NewKeyboardBuilder()
+				keyboard.AddRow().AddCallback("Yes", schemes.POSITIVE, "yes").AddCallback("No", schemes.NEGATIVE, "no")
+				reply.SetText("Hi, " + u.Message.Sender.Name + "! Continue?").AddKeyboard(keyboard)
+			case len(u.Message.Body.Attachments) > 0:
+				switch a := u.Message.Body.Attachments[0].(type) {
+				case *schemes.ContactAttachment:
+					reply.SetText("Contact of " + a.Payload.TamInfo.Name)
+				case *schemes.LocationAttachment:
+					reply.SetText(fmt.Sprintf("You are at %.2f, %.2f", a.Latitude, a.Longitude))
+				}
+			default:
+				continue
+			}
+			api.Messages.Send(ctx, reply)
+		case *schemes.MessageCallbackUpdate:
+			callback := maxbot.NewCallbackReply(api.Messages, u)
+			if u.Callback.Payload == "yes" {
+				callback.EditText(ctx, "Done")
+				callback.RemoveKeyboard(ctx)
+			} else {
+				callback.Notify(ctx, "Cancelled")
+			}
+		}
+	}
+}
+
+func TestUser_Conversation(t *testing.T) {
+	server := NewServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go runBot(ctx, server.Client(t))
+
+	alice := server.NewUser(t, "Alice")
+	alice.Send("/start").ExpectReply().HasText("Hi, Alice! Continue?").HasButtons("Yes", "No")
+
+	alice.Press("No").ExpectNotification("Cancelled")
+
+	edited := alice.Press("Yes").ExpectEdit()
+	edited.HasText("Done")
+
+	alice.ShareContact("Bob", "+70000000000").ExpectReply().HasText("Contact of Bob")
+	alice.ShareLocation(55.75, 37.62).ExpectReply().ContainsText("55.75, 37.62").HasNoKeyboard()
+
+	alice.Send("unknown")
+	alice.ExpectSilence()
+}
+
+func TestUser_Group(t *testing.T) {
+	server := NewServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go runBot(ctx, server.Client(t))
+
+	alice := server.NewUser(t, "Alice")
+	bob := server.NewUser(t, "Bob")
+	group := server.AddChat("Team", alice.User, bob.User)
+
+	bob.In(group).Send("/start").ExpectReply().HasText("Hi, Bob! Continue?")
+	alice.ExpectSilence()
+}
@@ -0,0 +1,152 @@
+package maxbot
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/pavmos/max-bot-api-client-go/schemes"
+)
+
+// sniffLength is the number of leading bytes http.DetectContentType looks at.
+const sniffLength = 512
+
+// isoAudioBrands are major brands of ISO-BMFF files holding audio only, see ftyp box.
+var isoAudioBrands = map[string]bool{"M4A ": true, "M4B ": true, "M4P ": true, "F4A ": true, "F4B ": true}
+
+// isoAudioExtensions are extensions of ISO-BMFF audio files, in case the system MIME table does not know them.
+var isoAudioExtensions = map[string]string{".m4a": "audio/mp4", ".m4b": "audio/mp4", ".m4p": "audio/mp4"}
+
+// DetectUploadType returns the upload type and the content type of the file by its leading bytes,
+// falling back to the extension of the name when the content is not recognized as any type.
+// Text content is a file whatever the extension is.
+func DetectUploadType(head []byte, name string) (schemes.UploadType, string) {
+	contentType := detectContentType(head, name)
+
+	return uploadTypeOf(contentType), contentType
+}
+
+func detectContentType(head []byte, name string) string {
+	contentType := http.DetectContentType(head)
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "application/octet-stream":
+		if byExtension := contentTypeByExtension(name); byExtension != "" {
+			return byExtension
+		}
+	case "video/mp4":
+		// http.DetectContentType reports any ISO-BMFF file as video, though M4A and other audio share the container.
+		if len(head) >= 12 && isoAudioBrands[string(head[8:12])] {
+			return "audio/mp4"
+		}
+		if byExtension := contentTypeByExtension(name); uploadTypeOf(byExtension) == schemes.AUDIO {
+			return byExtension
+		}
+	}
+
+	return contentType
+}
+
+func contentTypeByExtension(name string) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	if contentType := mime.TypeByExtension(ext); contentType != "" {
+		return contentType
+	}
+
+	return isoAudioExtensions[ext]
+}
+
+func uploadTypeOf(contentType string) schemes.UploadType {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case strings.HasPrefix(mediaType, "image/"):
+		return schemes.PHOTO
+	case strings.HasPrefix(mediaType, "video/"):
+		return schemes.VIDEO
+	case strings.HasPrefix(mediaType, "audio/"), mediaType == "application/ogg":
+		return schemes.AUDIO
+	}
+
+	return schemes.FILE
+}
+
+// setSizeLimit is safe to call concurrently with uploads.
+func (a *uploads) setSizeLimit(uploadType schemes.UploadType, limit int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.sizeLimits == nil {
+		a.sizeLimits = make(map[schemes.UploadType]int64)
+	}
+	a.sizeLimits[uploadType] = limit
+}
+
+func (a *uploads) sizeLimit(uploadType schemes.UploadType) int64 {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return a.sizeLimits[uploadType]
+}
+
+// validate checks that the content looks like the upload type and that its size, if known, fits the limit.
+// Files accept any content, and content that is not recognized passes as well.
+func (a *uploads) validate(uploadType schemes.UploadType, head []byte, name string, size int64) error {
+	if limit := a.sizeLimit(uploadType); limit > 0 && size > limit {
+		return fmt.Errorf("%w: %s is %d bytes, %s limit is %d bytes", ErrUploadTooLarge, uploadName(name), size, uploadType, limit)
+	}
+
+	if uploadType == schemes.FILE {
+		return nil
+	}
+	contentType := detectContentType(head, name)
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType == "application/octet-stream" {
+		return nil
+	}
+	if detected := uploadTypeOf(contentType); detected != uploadType {
+		return fmt.Errorf("%w: %s is %s, not %s", ErrUploadType, uploadName(name), contentType, uploadType)
+	}
+
+	return nil
+}
+
+// peekHead returns the leading bytes of the reader and the reader to upload instead of it: seekable readers are rewound,
+// other readers are buffered.
+func peekHead(reader io.Reader) ([]byte, io.Reader, error) {
+	if seeker, ok := reader.(io.ReadSeeker); ok {
+		offset, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, nil, err
+		}
+		head := make([]byte, sniffLength)
+		n, err := io.ReadFull(seeker, head)
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, nil, err
+		}
+		if _, err := seeker.Seek(offset, io.SeekStart); err != nil {
+			return nil, nil, err
+		}
+
+		return head[:n], reader, nil
+	}
+
+	buffered := bufio.NewReaderSize(reader, sniffLength)
+	head, err := buffered.Peek(sniffLength)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, nil, err
+	}
+
+	return head, buffered, nil
+}
+
+func uploadName(name string) string {
+	if name == "" {
+		return "content"
+	}
+
+	return filepath.Base(name)
+}
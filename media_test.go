@@ -0,0 +1,111 @@
+package maxbot
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavmos/max-bot-api-client-go/schemes"
+)
+
+var (
+	pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	m4aHeader = []byte("\x00\x00\x00\x20ftypM4A \x00\x00\x00\x00M4A mp42isom\x00\x00\x00\x00")
+)
+
+func TestDetectUploadType(t *testing.T) {
+	tests := []struct {
+		name     string
+		head     []byte
+		fileName string
+		want     schemes.UploadType
+	}{
+		{name: "png content", head: pngHeader, fileName: "picture.bin", want: schemes.PHOTO},
+		{name: "mp3 content", head: []byte("ID3\x03\x00\x00\x00"), want: schemes.AUDIO},
+		{name: "pdf content", head: []byte("%PDF-1.7\n"), fileName: "picture.jpg", want: schemes.FILE},
+		{name: "unknown content by extension", head: []byte{0, 1, 2, 3}, fileName: "clip.MP4", want: schemes.VIDEO},
+		{name: "text with media extension", head: []byte("not really"), fileName: "photo.jpg", want: schemes.FILE},
+		{name: "unknown content", head: []byte{0, 1, 2, 3}, want: schemes.FILE},
+		{name: "m4a content", head: m4aHeader, want: schemes.AUDIO},
+		{name: "mp4 container by m4a extension", head: []byte("\x00\x00\x00\x18ftypmp42\x00\x00\x00\x00mp42isom"), fileName: "voice.m4a", want: schemes.AUDIO},
+		{name: "mp4 content", head: []byte("\x00\x00\x00\x18ftypmp42\x00\x00\x00\x00mp42isom"), fileName: "clip.mp4", want: schemes.VIDEO},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := DetectUploadType(tt.head, tt.fileName)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestUploadValidation(t *testing.T) {
+	server := newUploadServer(t)
+	api := newTestApi(t, server.URL)
+	ctx := context.Background()
+
+	t.Run("type mismatch", func(t *testing.T) {
+		_, err := api.Uploads.UploadPhotoFromReaderWithName(ctx, bytes.NewReader([]byte("%PDF-1.7\n")), "report.pdf")
+		require.ErrorIs(t, err, ErrUploadType)
+		require.Contains(t, err.Error(), "report.pdf is application/pdf, not image")
+
+		_, err = api.Uploads.UploadMediaFromReader(ctx, schemes.FILE, bytes.NewReader([]byte("%PDF-1.7\n")))
+		require.NoError(t, err, "files accept any content")
+	})
+
+	t.Run("size limit", func(t *testing.T) {
+		api.SetUploadSizeLimit(schemes.VIDEO, 10)
+		t.Cleanup(func() { api.SetUploadSizeLimit(schemes.VIDEO, 0) })
+
+		_, err := api.Uploads.UploadMediaFromReader(ctx, schemes.VIDEO, bytes.NewReader(make([]byte, 11)))
+		require.ErrorIs(t, err, ErrUploadTooLarge)
+
+		_, err = api.Uploads.UploadMediaChunked(ctx, schemes.VIDEO, bytes.NewReader(make([]byte, 11)), 11, "video.mp4")
+		require.ErrorIs(t, err, ErrUploadTooLarge)
+	})
+
+	t.Run("text is not a photo", func(t *testing.T) {
+		_, err := api.Uploads.UploadPhotoFromReaderWithName(ctx, bytes.NewReader([]byte("not really")), "photo.jpg")
+		require.ErrorIs(t, err, ErrUploadType)
+	})
+
+	t.Run("m4a audio", func(t *testing.T) {
+		voice := filepath.Join(t.TempDir(), "voice.m4a")
+		require.NoError(t, os.WriteFile(voice, m4aHeader, 0o600))
+
+		_, err := api.Uploads.UploadMediaFromFile(ctx, schemes.AUDIO, voice)
+		require.NoError(t, err)
+	})
+
+	t.Run("without validation", func(t *testing.T) {
+		uploads := server.uploads
+		_, err := api.Uploads.UploadPhotoFromReader(ctx, bytes.NewReader([]byte("%PDF-1.7\n")), WithoutValidation())
+		require.NoError(t, err)
+		require.Equal(t, uploads+1, server.uploads)
+	})
+
+	require.Equal(t, 3, server.uploads, "invalid content must fail before any network call")
+}
+
+func TestUploadAuto(t *testing.T) {
+	server := newUploadServer(t)
+	api := newTestApi(t, server.URL)
+
+	dir := t.TempDir()
+	photo := filepath.Join(dir, "image.bin")
+	require.NoError(t, os.WriteFile(photo, pngHeader, 0o600))
+	doc := filepath.Join(dir, "notes.txt")
+	require.NoError(t, os.WriteFile(doc, []byte("notes"), 0o600))
+
+	attachment, err := api.Uploads.UploadAuto(context.Background(), photo)
+	require.NoError(t, err)
+	require.IsType(t, &schemes.PhotoAttachmentRequest{}, attachment)
+
+	attachment, err = api.Uploads.UploadAuto(context.Background(), doc)
+	require.NoError(t, err)
+	require.Equal(t, schemes.NewFileAttachmentRequest(schemes.UploadedInfo{Token: "token5"}), attachment)
+}
@@ -1,6 +1,10 @@
 package maxbot
 
-import "github.com/pavmos/max-bot-api-client-go/schemes"
+import (
+	"fmt"
+
+	"github.com/pavmos/max-bot-api-client-go/schemes"
+)
 
 type Message struct {
 	userID  int64
@@ -88,6 +92,33 @@ func (m *Message) AddPhoto(photo *schemes.PhotoTokens) *Message {
 	return m
 }
 
+// AddPhotoToken attaches the photo by the token of an existing attachment, e.g. one received in another message.
+func (m *Message) AddPhotoToken(token string) *Message {
+	m.message.Attachments = append(m.message.Attachments, schemes.NewPhotoAttachmentRequest(schemes.PhotoAttachmentRequestPayload{
+		Token: token,
+	}))
+
+	return m
+}
+
+// AddAttachment attaches the attachment request, e.g. returned by UploadAuto.
+func (m *Message) AddAttachment(attachment any) *Message {
+	m.message.Attachments = append(m.message.Attachments, attachment)
+
+	return m
+}
+
+// AddAlbum attaches the attachment requests returned by UploadMany, skipping the items that have failed.
+func (m *Message) AddAlbum(attachments []any) *Message {
+	for _, attachment := range attachments {
+		if attachment != nil {
+			m.message.Attachments = append(m.message.Attachments, attachment)
+		}
+	}
+
+	return m
+}
+
 func (m *Message) AddAudio(audio *schemes.UploadedInfo) *Message {
 	m.message.Attachments = append(m.message.Attachments, schemes.NewAudioAttachmentRequest(*audio))
 
@@ -130,3 +161,67 @@ func (m *Message) AddSticker(code string) *Message {
 
 	return m
 }
+
+// NewMessageFrom returns a message builder filled with the text, markup and attachments of the received message.
+// It is useful to edit a message keeping the parts that should stay untouched.
+// Attachments of unknown types are skipped.
+func NewMessageFrom(msg schemes.Message) *Message {
+	m, _ := newMessageFrom(msg)
+
+	return m
+}
+
+// newMessageFrom works like NewMessageFrom and returns ErrAttachmentNotResendable along with the message
+// if an attachment has been skipped.
+func newMessageFrom(msg schemes.Message) (*Message, error) {
+	m := NewMessage()
+	m.chatID = msg.Recipient.ChatId
+	if m.chatID == 0 {
+		m.userID = msg.Recipient.UserId
+	}
+	m.message.Text = msg.Body.Text
+	m.message.Markups = msg.Body.Markups
+
+	var err error
+	for _, a := range msg.Body.Attachments {
+		request := attachmentRequest(a)
+		if request == nil {
+			err = fmt.Errorf("%w: %T", ErrAttachmentNotResendable, a)
+			continue
+		}
+		m.message.Attachments = append(m.message.Attachments, request)
+	}
+
+	return m, err
+}
+
+// attachmentRequest converts a received attachment to the request attaching it again. It returns nil for attachments that cannot be resent.
+func attachmentRequest(attachment any) any {
+	switch a := attachment.(type) {
+	case *schemes.PhotoAttachment:
+		return schemes.NewPhotoAttachmentRequest(schemes.PhotoAttachmentRequestPayload{Token: a.Payload.Token})
+	case *schemes.VideoAttachment:
+		return schemes.NewVideoAttachmentRequest(schemes.UploadedInfo{Token: a.Payload.Token})
+	case *schemes.AudioAttachment:
+		return schemes.NewAudioAttachmentRequest(schemes.UploadedInfo{Token: a.Payload.Token})
+	case *schemes.FileAttachment:
+		return schemes.NewFileAttachmentRequest(schemes.UploadedInfo{Token: a.Payload.Token})
+	case *schemes.StickerAttachment:
+		return schemes.NewStickerAttachmentRequest(schemes.StickerAttachmentRequestPayload{Code: a.Payload.Code})
+	case *schemes.LocationAttachment:
+		return schemes.NewLocationAttachmentRequest(a.Latitude, a.Longitude)
+	case *schemes.ContactAttachment:
+		payload := schemes.ContactAttachmentRequestPayload{VcfInfo: a.Payload.VcfInfo}
+		if a.Payload.TamInfo != nil {
+			payload.ContactId = a.Payload.TamInfo.UserId
+			payload.Name = a.Payload.TamInfo.Name
+		}
+		return schemes.NewContactAttachmentRequest(payload)
+	case *schemes.ShareAttachment:
+		return schemes.NewShareAttachmentRequest(schemes.ShareAttachmentPayload{Url: a.Payload.Url})
+	case *schemes.InlineKeyboardAttachment:
+		return schemes.NewInlineKeyboardAttachmentRequest(a.Payload)
+	}
+
+	return nil
+}
@@ -2,23 +2,30 @@ package maxbot
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
-	"log/slog"
+	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pavmos/max-bot-api-client-go/schemes"
 )
 
 type messages struct {
 	client *client
+
+	attachmentRetries int
+	attachmentWait    time.Duration
 }
 
 func newMessages(client *client) *messages {
-	return &messages{client: client}
+	return &messages{
+		client:            client,
+		attachmentRetries: defaultAttachmentRetries,
+		attachmentWait:    defaultAttachmentWait,
+	}
 }
 
 // MessageResponse represents the response wrapper when a message is sent
@@ -57,11 +64,11 @@ func (a *messages) GetMessages(ctx context.Context, chatID int64, messageIDs []s
 	}
 	defer func() {
 		if err := body.Close(); err != nil {
-			slog.Error("failed to close response body", "error", err)
+			a.client.logger.Error("failed to close response body", "error", err)
 		}
 	}()
 
-	return result, json.NewDecoder(body).Decode(result)
+	return result, a.client.decode(body, result)
 }
 
 func (a *messages) GetMessage(ctx context.Context, messageID string) (*schemes.Message, error) {
@@ -73,11 +80,11 @@ func (a *messages) GetMessage(ctx context.Context, messageID string) (*schemes.M
 	}
 	defer func() {
 		if err := body.Close(); err != nil {
-			slog.Error("failed to close response body", "error", err)
+			a.client.logger.Error("failed to close response body", "error", err)
 		}
 	}()
 
-	return result, json.NewDecoder(body).Decode(result)
+	return result, a.client.decode(body, result)
 }
 
 // EditMessage updates the message by id.
@@ -104,11 +111,11 @@ func (a *messages) DeleteMessage(ctx context.Context, messageID string) (*scheme
 	}
 	defer func() {
 		if err := body.Close(); err != nil {
-			slog.Error("failed to close response body", "error", err)
+			a.client.logger.Error("failed to close response body", "error", err)
 		}
 	}()
 
-	return result, json.NewDecoder(body).Decode(result)
+	return result, a.client.decode(body, result)
 }
 
 // AnswerOnCallback should be called to send an answer after a user has clicked the button.
@@ -123,18 +130,11 @@ func (a *messages) AnswerOnCallback(ctx context.Context, callbackID string, call
 	}
 	defer func() {
 		if err := body.Close(); err != nil {
-			slog.Error("failed to close response body", "error", err)
+			a.client.logger.Error("failed to close response body", "error", err)
 		}
 	}()
 
-	return result, json.NewDecoder(body).Decode(result)
-}
-
-// NewKeyboardBuilder returns a new keyboard builder helper.
-func (a *messages) NewKeyboardBuilder() *Keyboard {
-	return &Keyboard{
-		rows: make([]*KeyboardRow, 0),
-	}
+	return result, a.client.decode(body, result)
 }
 
 // Send sends a message to the chat. A new message identifier returns if no error.
@@ -149,6 +149,62 @@ func (a *messages) SendWithResult(ctx context.Context, m *Message) (*schemes.Mes
 	return a.sendMessage(ctx, m.reset, m.chatID, m.userID, m.message)
 }
 
+// SendWhenReady sends a message with just uploaded video, audio or file attachments. The server processes them
+// asynchronously and rejects the message until they are ready, so the sending is retried with a growing pause,
+// see Api.SetAttachmentRetry.
+func (a *messages) SendWhenReady(ctx context.Context, m *Message) (*schemes.Message, error) {
+	wait := a.attachmentWait
+	for attempt := 0; ; attempt++ {
+		result, err := a.sendMessage(ctx, m.reset, m.chatID, m.userID, m.message)
+		if err == nil || !isAttachmentNotReady(err) {
+			return result, err
+		}
+		if attempt >= a.attachmentRetries {
+			return nil, fmt.Errorf("attachments are not ready after %d attempts: %w", attempt+1, err)
+		}
+
+		a.client.logger.Debug("attachment is not ready, retrying", "attempt", attempt+1, "wait", wait)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+		wait = min(wait*2, maxAttachmentWait)
+	}
+}
+
+func (a *messages) setAttachmentRetry(retries int, wait time.Duration) {
+	if wait <= 0 {
+		wait = defaultAttachmentWait
+	}
+	a.attachmentRetries = max(retries, 0)
+	a.attachmentWait = wait
+}
+
+// GetVideoDetails returns playback URLs and metadata of the video by its attachment token.
+func (a *messages) GetVideoDetails(ctx context.Context, videoToken string) (*schemes.VideoAttachmentDetails, error) {
+	result := new(schemes.VideoAttachmentDetails)
+	body, err := a.client.request(ctx, http.MethodGet, fmt.Sprintf(formatPathVideos, url.PathEscape(videoToken)), nil, false, nil)
+	if err != nil {
+		return result, err
+	}
+	defer func() {
+		if err := body.Close(); err != nil {
+			a.client.logger.Error("failed to close response body", "error", err)
+		}
+	}()
+
+	return result, a.client.decode(body, result)
+}
+
+// isAttachmentNotReady reports whether the server has rejected the message because its attachments are still processed.
+// Only the documented error code is matched, other errors are returned at once.
+func isAttachmentNotReady(err error) bool {
+	var apiErr *APIError
+
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusBadRequest && apiErr.Message == errCodeAttachmentNotReady
+}
+
 func (a *messages) sendMessage(ctx context.Context, reset bool, chatID int64, userID int64, message *schemes.NewMessageBody) (*schemes.Message, error) {
 	wrapper := new(MessageResponse)
 	values := url.Values{}
@@ -165,11 +221,11 @@ func (a *messages) sendMessage(ctx context.Context, reset bool, chatID int64, us
 	}
 	defer func() {
 		if err := body.Close(); err != nil {
-			slog.Error("failed to close response body", "error", err)
+			a.client.logger.Error("failed to close response body", "error", err)
 		}
 	}()
 
-	if err := json.NewDecoder(body).Decode(wrapper); err != nil {
+	if err := a.client.decode(body, wrapper); err != nil {
 		return nil, err
 	}
 
@@ -186,11 +242,11 @@ func (a *messages) editMessage(ctx context.Context, messageID string, message *s
 	}
 	defer func() {
 		if err := body.Close(); err != nil {
-			slog.Error("failed to close response body", "error", err)
+			a.client.logger.Error("failed to close response body", "error", err)
 		}
 	}()
 
-	return result, json.NewDecoder(body).Decode(result)
+	return result, a.client.decode(body, result)
 }
 
 // Check posiable to send a message to a chat.
@@ -215,11 +271,11 @@ func (a *messages) checkUser(ctx context.Context, reset bool, message *schemes.N
 	}
 	defer func() {
 		if err := body.Close(); err != nil {
-			slog.Error("failed to close response body", "error", err)
+			a.client.logger.Error("failed to close response body", "error", err)
 		}
 	}()
 
-	if err := json.NewDecoder(body).Decode(result); err != nil {
+	if err := a.client.decode(body, result); err != nil {
 		return false, err
 	}
 
@@ -251,7 +307,7 @@ func (a *messages) checkNumberExist(ctx context.Context, reset bool, message *sc
 		return nil, err
 	}
 	defer body.Close()
-	if err := json.NewDecoder(body).Decode(result); err != nil {
+	if err := a.client.decode(body, result); err != nil {
 		// Message sent without errors
 		return nil, err
 	}
@@ -0,0 +1,107 @@
+package maxbot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavmos/max-bot-api-client-go/schemes"
+)
+
+func TestSendWhenReady(t *testing.T) {
+	tests := []struct {
+		name      string
+		notReady  int
+		errorCode string
+		errorText string
+		retries   int
+		wantSends int
+		wantErr   bool
+	}{
+		{name: "ready at once", wantSends: 1, retries: 3},
+		{name: "ready after retries", notReady: 2, errorCode: "attachment.not.ready", retries: 3, wantSends: 3},
+		{name: "never ready", notReady: 10, errorCode: "attachment.not.ready", retries: 2, wantSends: 3, wantErr: true},
+		{name: "other code is not retried", notReady: 10, errorCode: "bad.request", errorText: "Key: errors.process.attachment.file.not.processed", retries: 3, wantSends: 1, wantErr: true},
+		{name: "other error", notReady: 10, errorCode: "proto.payload", errorText: "Invalid payload", retries: 3, wantSends: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sends := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				sends++
+				if sends <= tt.notReady {
+					w.WriteHeader(http.StatusBadRequest)
+					json.NewEncoder(w).Encode(schemes.Error{Code: tt.errorCode, Message: tt.errorText})
+					return
+				}
+				json.NewEncoder(w).Encode(MessageResponse{Message: schemes.Message{Body: schemes.MessageBody{Mid: "mid"}}})
+			}))
+			defer server.Close()
+
+			api := newTestApi(t, server.URL)
+			api.SetAttachmentRetry(tt.retries, time.Millisecond)
+
+			msg := NewMessage().SetChat(1).AddVideo(&schemes.UploadedInfo{Token: "video"})
+			result, err := api.Messages.SendWhenReady(context.Background(), msg)
+			require.Equal(t, tt.wantSends, sends)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "mid", result.Body.Mid)
+		})
+	}
+}
+
+func TestSetAttachmentRetry(t *testing.T) {
+	tests := []struct {
+		name        string
+		retries     int
+		wait        time.Duration
+		wantRetries int
+		wantWait    time.Duration
+	}{
+		{name: "custom", retries: 3, wait: time.Second, wantRetries: 3, wantWait: time.Second},
+		{name: "zero wait", retries: 3, wait: 0, wantRetries: 3, wantWait: defaultAttachmentWait},
+		{name: "negative", retries: -1, wait: -time.Second, wantRetries: 0, wantWait: defaultAttachmentWait},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMessages(nil)
+			m.setAttachmentRetry(tt.retries, tt.wait)
+			require.Equal(t, tt.wantRetries, m.attachmentRetries)
+			require.Equal(t, tt.wantWait, m.attachmentWait)
+		})
+	}
+}
+
+func TestGetVideoDetails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/videos/abc-123" {
+			http.Error(w, "unexpected request", http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"token":"abc-123","urls":{"mp4_720":"https://example.com/720.mp4","hls":"https://example.com/live.m3u8"},
+			"thumbnail":{"url":"https://example.com/thumb.jpg"},"width":1280,"height":720,"duration":42}`))
+	}))
+	defer server.Close()
+
+	api := newTestApi(t, server.URL)
+	details, err := api.Messages.GetVideoDetails(context.Background(), "abc-123")
+	require.NoError(t, err)
+	require.Equal(t, &schemes.VideoAttachmentDetails{
+		Token:     "abc-123",
+		Urls:      &schemes.VideoUrls{Mp4720: "https://example.com/720.mp4", Hls: "https://example.com/live.m3u8"},
+		Thumbnail: &schemes.PhotoAttachmentPayload{Url: "https://example.com/thumb.jpg"},
+		Width:     1280,
+		Height:    720,
+		Duration:  42,
+	}, details)
+}
@@ -0,0 +1,99 @@
+package maxbot
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/pavmos/max-bot-api-client-go/schemes"
+)
+
+// Metrics receives measurements of the client. Methods are called synchronously from the goroutines sending requests
+// and receiving updates, so they must be safe for concurrent use and return quickly.
+// ExpvarMetrics and PrometheusMetrics are the built-in implementations.
+type Metrics interface {
+	// ObserveRequest is called after every request to the API. Path is a template without identifiers,
+	// e.g. "chats/{chat_id}/members", status is zero if no response has been received.
+	ObserveRequest(method, path string, status int, latency time.Duration)
+	// ObservePoll is called after every long polling request of GetUpdates with the number of received updates.
+	ObservePoll(latency time.Duration, updates int, err error)
+	// ObserveUpdate is called for every update received by GetUpdates or GetHandler. Err is the parse failure,
+	// the type is empty if it cannot be determined.
+	ObserveUpdate(updateType schemes.UpdateType, err error)
+	// ObserveHandler is called after a handler dispatched by CommandRegistry.Handle or CallbackReply.Handle returns.
+	// Handler is the command, e.g. "/start", or "callback".
+	ObserveHandler(handler string, latency time.Duration, err error)
+}
+
+const callbackHandler = "callback"
+
+// SetMetrics sets the receiver of measurements of requests, polling, updates and handlers, nil disables them.
+func (a *Api) SetMetrics(metrics Metrics) {
+	a.client.metrics = metrics
+}
+
+func (cl *client) observeRequest(method, path string, status int, latency time.Duration) {
+	if cl.metrics == nil {
+		return
+	}
+	cl.metrics.ObserveRequest(method, metricPath(path), status, latency)
+}
+
+func (cl *client) observePoll(latency time.Duration, result *schemes.UpdateList, err error) {
+	if cl.metrics == nil {
+		return
+	}
+	updates := 0
+	if result != nil {
+		updates = len(result.Updates)
+	}
+	cl.metrics.ObservePoll(latency, updates, err)
+}
+
+// observeUpdate reports the parsed update or, if parsing failed, the type found in the raw update.
+func (cl *client) observeUpdate(data []byte, update schemes.UpdateInterface, err error) {
+	if cl.metrics == nil {
+		return
+	}
+	if err == nil {
+		cl.metrics.ObserveUpdate(update.GetUpdateType(), nil)
+		return
+	}
+
+	baseUpdate := &schemes.Update{}
+	_ = json.Unmarshal(data, baseUpdate)
+	cl.metrics.ObserveUpdate(baseUpdate.GetUpdateType(), err)
+}
+
+// observeHandler runs the handler and reports its latency and error.
+// The client is nil for helpers created by mocks of the sub-clients.
+func (cl *client) observeHandler(handler string, fn func() error) error {
+	if cl == nil || cl.metrics == nil {
+		return fn()
+	}
+
+	start := time.Now()
+	err := fn()
+	cl.metrics.ObserveHandler(handler, time.Since(start), err)
+
+	return err
+}
+
+// metricPath replaces identifiers in the request path with placeholders, keeping the number of distinct paths small.
+func metricPath(path string) string {
+	segments := strings.Split(path, "/")
+	if len(segments) < 2 {
+		return path
+	}
+
+	switch segments[0] {
+	case pathChats:
+		segments[1] = "{chat_id}"
+	case pathMessages:
+		segments[1] = "{message_id}"
+	case pathVideos:
+		segments[1] = "{video_token}"
+	}
+
+	return strings.Join(segments, "/")
+}
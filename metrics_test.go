@@ -0,0 +1,182 @@
+package maxbot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavmos/max-bot-api-client-go/schemes"
+)
+
+// recordingMetrics records observations without latencies.
+type recordingMetrics struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (m *recordingMetrics) record(format string, args ...any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.events = append(m.events, fmt.Sprintf(format, args...))
+}
+
+func (m *recordingMetrics) Events() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]string(nil), m.events...)
+}
+
+func (m *recordingMetrics) ObserveRequest(method, path string, status int, _ time.Duration) {
+	m.record("request %s %s %d", method, path, status)
+}
+
+func (m *recordingMetrics) ObservePoll(_ time.Duration, updates int, err error) {
+	m.record("poll %d %v", updates, err)
+}
+
+func (m *recordingMetrics) ObserveUpdate(updateType schemes.UpdateType, err error) {
+	m.record("update %q %t", updateType, err != nil)
+}
+
+func (m *recordingMetrics) ObserveHandler(handler string, _ time.Duration, err error) {
+	m.record("handler %s %v", handler, err)
+}
+
+func TestMetrics(t *testing.T) {
+	update, err := json.Marshal(schemes.MessageCreatedUpdate{
+		Update:  schemes.Update{UpdateType: schemes.TypeMessageCreated},
+		Message: schemes.Message{Body: schemes.MessageBody{Mid: "mid.1", Text: "/ping"}},
+	})
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/updates":
+			marker := int64(1)
+			list := schemes.UpdateList{Marker: &marker, Updates: []json.RawMessage{}}
+			if r.URL.Query().Get(paramMarker) == "" {
+				list.Updates = []json.RawMessage{update, json.RawMessage(`{"update_type":"message_edited","message":1}`)}
+			}
+			json.NewEncoder(w).Encode(list)
+		case "/chats/1":
+			json.NewEncoder(w).Encode(schemes.Chat{ChatId: 1})
+		case "/answers":
+			json.NewEncoder(w).Encode(schemes.SimpleQueryResult{Success: true})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	metrics := &recordingMetrics{}
+	api := newTestApi(t, server.URL)
+	api.pause = 10 * time.Millisecond
+	api.SetMetrics(metrics)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var upd schemes.UpdateInterface
+	select {
+	case upd = <-api.GetUpdates(ctx):
+	case <-ctx.Done():
+		t.Fatal("no update received in time")
+	}
+	cancel()
+	ctx = context.Background()
+
+	_, err = api.Chats.GetChat(ctx, 1)
+	require.NoError(t, err)
+	_, err = api.Chats.GetChat(ctx, 2)
+	require.Error(t, err)
+
+	errPing := errors.New("ping failed")
+	registry := api.NewCommandRegistry().Register(CommandSpec{
+		Name: "ping",
+		Handler: func(context.Context, *schemes.MessageCreatedUpdate, *Command, any) error {
+			return errPing
+		},
+	})
+	handled, err := registry.Handle(ctx, upd.(*schemes.MessageCreatedUpdate))
+	require.True(t, handled)
+	require.ErrorIs(t, err, errPing)
+
+	callback := &schemes.MessageCallbackUpdate{Callback: schemes.Callback{CallbackID: "cb"}}
+	require.NoError(t, NewCallbackReply(api.Messages, callback).Handle(ctx, 0, func(ctx context.Context, r *CallbackReply) error {
+		return r.Ack(ctx)
+	}))
+
+	events := metrics.Events()
+	require.Equal(t, []string{
+		"request GET updates 200",
+		"poll 2 <nil>",
+		`update "message_created" false`,
+		`update "message_edited" true`,
+	}, events[:4])
+	require.Equal(t, []string{
+		"request GET chats/{chat_id} 200",
+		"request GET chats/{chat_id} 404",
+		"handler /ping ping failed",
+		"request POST answers 200",
+		"handler callback <nil>",
+	}, events[len(events)-5:])
+}
+
+func TestMetricPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "me", want: "me"},
+		{path: "chats", want: "chats"},
+		{path: "chats/-42", want: "chats/{chat_id}"},
+		{path: "chats/42/members/admins", want: "chats/{chat_id}/members/admins"},
+		{path: "messages/mid.0123", want: "messages/{message_id}"},
+		{path: "videos/token", want: "videos/{video_token}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			require.Equal(t, tt.want, metricPath(tt.path))
+		})
+	}
+}
+
+func TestExpvarMetrics(t *testing.T) {
+	metrics := NewExpvarMetrics("maxbot_test")
+	metrics.ObserveRequest(http.MethodGet, "me", http.StatusOK, 250*time.Millisecond)
+	metrics.ObservePoll(time.Second, 3, nil)
+	metrics.ObservePoll(time.Second, 0, errors.New("timeout"))
+	metrics.ObserveUpdate(schemes.TypeMessageCreated, nil)
+	metrics.ObserveUpdate("", errors.New("invalid"))
+	metrics.ObserveHandler("/start", 500*time.Millisecond, errors.New("failed"))
+
+	// Metrics with the same name continue the counters.
+	NewExpvarMetrics("maxbot_test").ObserveRequest(http.MethodGet, "me", http.StatusOK, 250*time.Millisecond)
+
+	var got map[string]any
+	require.NoError(t, json.Unmarshal([]byte(expvar.Get("maxbot_test").String()), &got))
+	require.Equal(t, map[string]any{
+		"requests":        map[string]any{"GET me 200": 2.0},
+		"request_seconds": map[string]any{"GET me 200": 0.5},
+		"polls":           2.0,
+		"poll_errors":     1.0,
+		"poll_seconds":    2.0,
+		"poll_updates":    3.0,
+		"updates":         map[string]any{"message_created": 1.0},
+		"parse_failures":  map[string]any{"unknown": 1.0},
+		"handlers":        map[string]any{"/start": 1.0},
+		"handler_errors":  map[string]any{"/start": 1.0},
+		"handler_seconds": map[string]any{"/start": 0.5},
+	}, got)
+}
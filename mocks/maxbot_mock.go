@@ -0,0 +1,856 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: interfaces.go
+//
+// Generated by this command:
+//
+//	mockgen -source=interfaces.go -destination=./mocks/maxbot_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	io "io"
+	url "net/url"
+	reflect "reflect"
+
+	maxbot "github.com/pavmos/max-bot-api-client-go"
+	schemes "github.com/pavmos/max-bot-api-client-go/schemes"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockBotsAPI is a mock of BotsAPI interface.
+type MockBotsAPI struct {
+	ctrl     *gomock.Controller
+	recorder *MockBotsAPIMockRecorder
+	isgomock struct{}
+}
+
+// MockBotsAPIMockRecorder is the mock recorder for MockBotsAPI.
+type MockBotsAPIMockRecorder struct {
+	mock *MockBotsAPI
+}
+
+// NewMockBotsAPI creates a new mock instance.
+func NewMockBotsAPI(ctrl *gomock.Controller) *MockBotsAPI {
+	mock := &MockBotsAPI{ctrl: ctrl}
+	mock.recorder = &MockBotsAPIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBotsAPI) EXPECT() *MockBotsAPIMockRecorder {
+	return m.recorder
+}
+
+// GetBot mocks base method.
+func (m *MockBotsAPI) GetBot(ctx context.Context) (*schemes.BotInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBot", ctx)
+	ret0, _ := ret[0].(*schemes.BotInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBot indicates an expected call of GetBot.
+func (mr *MockBotsAPIMockRecorder) GetBot(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBot", reflect.TypeOf((*MockBotsAPI)(nil).GetBot), ctx)
+}
+
+// PatchBot mocks base method.
+func (m *MockBotsAPI) PatchBot(ctx context.Context, patch *schemes.BotPatch) (*schemes.BotInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchBot", ctx, patch)
+	ret0, _ := ret[0].(*schemes.BotInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PatchBot indicates an expected call of PatchBot.
+func (mr *MockBotsAPIMockRecorder) PatchBot(ctx, patch any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchBot", reflect.TypeOf((*MockBotsAPI)(nil).PatchBot), ctx, patch)
+}
+
+// MockChatsAPI is a mock of ChatsAPI interface.
+type MockChatsAPI struct {
+	ctrl     *gomock.Controller
+	recorder *MockChatsAPIMockRecorder
+	isgomock struct{}
+}
+
+// MockChatsAPIMockRecorder is the mock recorder for MockChatsAPI.
+type MockChatsAPIMockRecorder struct {
+	mock *MockChatsAPI
+}
+
+// NewMockChatsAPI creates a new mock instance.
+func NewMockChatsAPI(ctrl *gomock.Controller) *MockChatsAPI {
+	mock := &MockChatsAPI{ctrl: ctrl}
+	mock.recorder = &MockChatsAPIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockChatsAPI) EXPECT() *MockChatsAPIMockRecorder {
+	return m.recorder
+}
+
+// AddMember mocks base method.
+func (m *MockChatsAPI) AddMember(ctx context.Context, chatID int64, users schemes.UserIdsList) (*schemes.SimpleQueryResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddMember", ctx, chatID, users)
+	ret0, _ := ret[0].(*schemes.SimpleQueryResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddMember indicates an expected call of AddMember.
+func (mr *MockChatsAPIMockRecorder) AddMember(ctx, chatID, users any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddMember", reflect.TypeOf((*MockChatsAPI)(nil).AddMember), ctx, chatID, users)
+}
+
+// EditChat mocks base method.
+func (m *MockChatsAPI) EditChat(ctx context.Context, chatID int64, update *schemes.ChatPatch) (*schemes.Chat, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EditChat", ctx, chatID, update)
+	ret0, _ := ret[0].(*schemes.Chat)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EditChat indicates an expected call of EditChat.
+func (mr *MockChatsAPIMockRecorder) EditChat(ctx, chatID, update any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EditChat", reflect.TypeOf((*MockChatsAPI)(nil).EditChat), ctx, chatID, update)
+}
+
+// GetChat mocks base method.
+func (m *MockChatsAPI) GetChat(ctx context.Context, chatID int64) (*schemes.Chat, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChat", ctx, chatID)
+	ret0, _ := ret[0].(*schemes.Chat)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChat indicates an expected call of GetChat.
+func (mr *MockChatsAPIMockRecorder) GetChat(ctx, chatID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChat", reflect.TypeOf((*MockChatsAPI)(nil).GetChat), ctx, chatID)
+}
+
+// GetChatAdmins mocks base method.
+func (m *MockChatsAPI) GetChatAdmins(ctx context.Context, chatID int64) (*schemes.ChatMembersList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChatAdmins", ctx, chatID)
+	ret0, _ := ret[0].(*schemes.ChatMembersList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChatAdmins indicates an expected call of GetChatAdmins.
+func (mr *MockChatsAPIMockRecorder) GetChatAdmins(ctx, chatID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChatAdmins", reflect.TypeOf((*MockChatsAPI)(nil).GetChatAdmins), ctx, chatID)
+}
+
+// GetChatMembers mocks base method.
+func (m *MockChatsAPI) GetChatMembers(ctx context.Context, chatID, count, marker int64) (*schemes.ChatMembersList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChatMembers", ctx, chatID, count, marker)
+	ret0, _ := ret[0].(*schemes.ChatMembersList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChatMembers indicates an expected call of GetChatMembers.
+func (mr *MockChatsAPIMockRecorder) GetChatMembers(ctx, chatID, count, marker any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChatMembers", reflect.TypeOf((*MockChatsAPI)(nil).GetChatMembers), ctx, chatID, count, marker)
+}
+
+// GetChatMembership mocks base method.
+func (m *MockChatsAPI) GetChatMembership(ctx context.Context, chatID int64) (*schemes.ChatMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChatMembership", ctx, chatID)
+	ret0, _ := ret[0].(*schemes.ChatMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChatMembership indicates an expected call of GetChatMembership.
+func (mr *MockChatsAPIMockRecorder) GetChatMembership(ctx, chatID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChatMembership", reflect.TypeOf((*MockChatsAPI)(nil).GetChatMembership), ctx, chatID)
+}
+
+// GetChats mocks base method.
+func (m *MockChatsAPI) GetChats(ctx context.Context, count, marker int64) (*schemes.ChatList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChats", ctx, count, marker)
+	ret0, _ := ret[0].(*schemes.ChatList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChats indicates an expected call of GetChats.
+func (mr *MockChatsAPIMockRecorder) GetChats(ctx, count, marker any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChats", reflect.TypeOf((*MockChatsAPI)(nil).GetChats), ctx, count, marker)
+}
+
+// GetSpecificChatMembers mocks base method.
+func (m *MockChatsAPI) GetSpecificChatMembers(ctx context.Context, chatID int64, userIDs []int64) (*schemes.ChatMembersList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSpecificChatMembers", ctx, chatID, userIDs)
+	ret0, _ := ret[0].(*schemes.ChatMembersList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSpecificChatMembers indicates an expected call of GetSpecificChatMembers.
+func (mr *MockChatsAPIMockRecorder) GetSpecificChatMembers(ctx, chatID, userIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSpecificChatMembers", reflect.TypeOf((*MockChatsAPI)(nil).GetSpecificChatMembers), ctx, chatID, userIDs)
+}
+
+// LeaveChat mocks base method.
+func (m *MockChatsAPI) LeaveChat(ctx context.Context, chatID int64) (*schemes.SimpleQueryResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LeaveChat", ctx, chatID)
+	ret0, _ := ret[0].(*schemes.SimpleQueryResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LeaveChat indicates an expected call of LeaveChat.
+func (mr *MockChatsAPIMockRecorder) LeaveChat(ctx, chatID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LeaveChat", reflect.TypeOf((*MockChatsAPI)(nil).LeaveChat), ctx, chatID)
+}
+
+// RemoveMember mocks base method.
+func (m *MockChatsAPI) RemoveMember(ctx context.Context, chatID, userID int64) (*schemes.SimpleQueryResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveMember", ctx, chatID, userID)
+	ret0, _ := ret[0].(*schemes.SimpleQueryResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveMember indicates an expected call of RemoveMember.
+func (mr *MockChatsAPIMockRecorder) RemoveMember(ctx, chatID, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMember", reflect.TypeOf((*MockChatsAPI)(nil).RemoveMember), ctx, chatID, userID)
+}
+
+// SendAction mocks base method.
+func (m *MockChatsAPI) SendAction(ctx context.Context, chatID int64, action schemes.SenderAction) (*schemes.SimpleQueryResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendAction", ctx, chatID, action)
+	ret0, _ := ret[0].(*schemes.SimpleQueryResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendAction indicates an expected call of SendAction.
+func (mr *MockChatsAPIMockRecorder) SendAction(ctx, chatID, action any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendAction", reflect.TypeOf((*MockChatsAPI)(nil).SendAction), ctx, chatID, action)
+}
+
+// MockDebugsAPI is a mock of DebugsAPI interface.
+type MockDebugsAPI struct {
+	ctrl     *gomock.Controller
+	recorder *MockDebugsAPIMockRecorder
+	isgomock struct{}
+}
+
+// MockDebugsAPIMockRecorder is the mock recorder for MockDebugsAPI.
+type MockDebugsAPIMockRecorder struct {
+	mock *MockDebugsAPI
+}
+
+// NewMockDebugsAPI creates a new mock instance.
+func NewMockDebugsAPI(ctrl *gomock.Controller) *MockDebugsAPI {
+	mock := &MockDebugsAPI{ctrl: ctrl}
+	mock.recorder = &MockDebugsAPIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDebugsAPI) EXPECT() *MockDebugsAPIMockRecorder {
+	return m.recorder
+}
+
+// Send mocks base method.
+func (m *MockDebugsAPI) Send(ctx context.Context, upd schemes.UpdateInterface) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", ctx, upd)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockDebugsAPIMockRecorder) Send(ctx, upd any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockDebugsAPI)(nil).Send), ctx, upd)
+}
+
+// SendErr mocks base method.
+func (m *MockDebugsAPI) SendErr(ctx context.Context, err error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendErr", ctx, err)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendErr indicates an expected call of SendErr.
+func (mr *MockDebugsAPIMockRecorder) SendErr(ctx, err any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendErr", reflect.TypeOf((*MockDebugsAPI)(nil).SendErr), ctx, err)
+}
+
+// MockMessagesAPI is a mock of MessagesAPI interface.
+type MockMessagesAPI struct {
+	ctrl     *gomock.Controller
+	recorder *MockMessagesAPIMockRecorder
+	isgomock struct{}
+}
+
+// MockMessagesAPIMockRecorder is the mock recorder for MockMessagesAPI.
+type MockMessagesAPIMockRecorder struct {
+	mock *MockMessagesAPI
+}
+
+// NewMockMessagesAPI creates a new mock instance.
+func NewMockMessagesAPI(ctrl *gomock.Controller) *MockMessagesAPI {
+	mock := &MockMessagesAPI{ctrl: ctrl}
+	mock.recorder = &MockMessagesAPIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMessagesAPI) EXPECT() *MockMessagesAPIMockRecorder {
+	return m.recorder
+}
+
+// AnswerOnCallback mocks base method.
+func (m *MockMessagesAPI) AnswerOnCallback(ctx context.Context, callbackID string, callback *schemes.CallbackAnswer) (*schemes.SimpleQueryResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnswerOnCallback", ctx, callbackID, callback)
+	ret0, _ := ret[0].(*schemes.SimpleQueryResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AnswerOnCallback indicates an expected call of AnswerOnCallback.
+func (mr *MockMessagesAPIMockRecorder) AnswerOnCallback(ctx, callbackID, callback any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnswerOnCallback", reflect.TypeOf((*MockMessagesAPI)(nil).AnswerOnCallback), ctx, callbackID, callback)
+}
+
+// Check mocks base method.
+func (m_2 *MockMessagesAPI) Check(ctx context.Context, m *maxbot.Message) (bool, error) {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "Check", ctx, m)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Check indicates an expected call of Check.
+func (mr *MockMessagesAPIMockRecorder) Check(ctx, m any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockMessagesAPI)(nil).Check), ctx, m)
+}
+
+// DeleteMessage mocks base method.
+func (m *MockMessagesAPI) DeleteMessage(ctx context.Context, messageID string) (*schemes.SimpleQueryResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMessage", ctx, messageID)
+	ret0, _ := ret[0].(*schemes.SimpleQueryResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteMessage indicates an expected call of DeleteMessage.
+func (mr *MockMessagesAPIMockRecorder) DeleteMessage(ctx, messageID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMessage", reflect.TypeOf((*MockMessagesAPI)(nil).DeleteMessage), ctx, messageID)
+}
+
+// EditMessage mocks base method.
+func (m *MockMessagesAPI) EditMessage(ctx context.Context, messageID string, message *maxbot.Message) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EditMessage", ctx, messageID, message)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EditMessage indicates an expected call of EditMessage.
+func (mr *MockMessagesAPIMockRecorder) EditMessage(ctx, messageID, message any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EditMessage", reflect.TypeOf((*MockMessagesAPI)(nil).EditMessage), ctx, messageID, message)
+}
+
+// GetMessage mocks base method.
+func (m *MockMessagesAPI) GetMessage(ctx context.Context, messageID string) (*schemes.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMessage", ctx, messageID)
+	ret0, _ := ret[0].(*schemes.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMessage indicates an expected call of GetMessage.
+func (mr *MockMessagesAPIMockRecorder) GetMessage(ctx, messageID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMessage", reflect.TypeOf((*MockMessagesAPI)(nil).GetMessage), ctx, messageID)
+}
+
+// GetMessages mocks base method.
+func (m *MockMessagesAPI) GetMessages(ctx context.Context, chatID int64, messageIDs []string, from, to, count int) (*schemes.MessageList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMessages", ctx, chatID, messageIDs, from, to, count)
+	ret0, _ := ret[0].(*schemes.MessageList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMessages indicates an expected call of GetMessages.
+func (mr *MockMessagesAPIMockRecorder) GetMessages(ctx, chatID, messageIDs, from, to, count any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMessages", reflect.TypeOf((*MockMessagesAPI)(nil).GetMessages), ctx, chatID, messageIDs, from, to, count)
+}
+
+// GetVideoDetails mocks base method.
+func (m *MockMessagesAPI) GetVideoDetails(ctx context.Context, videoToken string) (*schemes.VideoAttachmentDetails, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVideoDetails", ctx, videoToken)
+	ret0, _ := ret[0].(*schemes.VideoAttachmentDetails)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVideoDetails indicates an expected call of GetVideoDetails.
+func (mr *MockMessagesAPIMockRecorder) GetVideoDetails(ctx, videoToken any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVideoDetails", reflect.TypeOf((*MockMessagesAPI)(nil).GetVideoDetails), ctx, videoToken)
+}
+
+// ListExist mocks base method.
+func (m_2 *MockMessagesAPI) ListExist(ctx context.Context, m *maxbot.Message) ([]string, error) {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "ListExist", ctx, m)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExist indicates an expected call of ListExist.
+func (mr *MockMessagesAPIMockRecorder) ListExist(ctx, m any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExist", reflect.TypeOf((*MockMessagesAPI)(nil).ListExist), ctx, m)
+}
+
+// Send mocks base method.
+func (m_2 *MockMessagesAPI) Send(ctx context.Context, m *maxbot.Message) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "Send", ctx, m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockMessagesAPIMockRecorder) Send(ctx, m any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockMessagesAPI)(nil).Send), ctx, m)
+}
+
+// SendWhenReady mocks base method.
+func (m_2 *MockMessagesAPI) SendWhenReady(ctx context.Context, m *maxbot.Message) (*schemes.Message, error) {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendWhenReady", ctx, m)
+	ret0, _ := ret[0].(*schemes.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendWhenReady indicates an expected call of SendWhenReady.
+func (mr *MockMessagesAPIMockRecorder) SendWhenReady(ctx, m any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendWhenReady", reflect.TypeOf((*MockMessagesAPI)(nil).SendWhenReady), ctx, m)
+}
+
+// SendWithResult mocks base method.
+func (m_2 *MockMessagesAPI) SendWithResult(ctx context.Context, m *maxbot.Message) (*schemes.Message, error) {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendWithResult", ctx, m)
+	ret0, _ := ret[0].(*schemes.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendWithResult indicates an expected call of SendWithResult.
+func (mr *MockMessagesAPIMockRecorder) SendWithResult(ctx, m any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendWithResult", reflect.TypeOf((*MockMessagesAPI)(nil).SendWithResult), ctx, m)
+}
+
+// MockSubscriptionsAPI is a mock of SubscriptionsAPI interface.
+type MockSubscriptionsAPI struct {
+	ctrl     *gomock.Controller
+	recorder *MockSubscriptionsAPIMockRecorder
+	isgomock struct{}
+}
+
+// MockSubscriptionsAPIMockRecorder is the mock recorder for MockSubscriptionsAPI.
+type MockSubscriptionsAPIMockRecorder struct {
+	mock *MockSubscriptionsAPI
+}
+
+// NewMockSubscriptionsAPI creates a new mock instance.
+func NewMockSubscriptionsAPI(ctrl *gomock.Controller) *MockSubscriptionsAPI {
+	mock := &MockSubscriptionsAPI{ctrl: ctrl}
+	mock.recorder = &MockSubscriptionsAPIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSubscriptionsAPI) EXPECT() *MockSubscriptionsAPIMockRecorder {
+	return m.recorder
+}
+
+// GetSubscriptions mocks base method.
+func (m *MockSubscriptionsAPI) GetSubscriptions(ctx context.Context) (*schemes.GetSubscriptionsResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubscriptions", ctx)
+	ret0, _ := ret[0].(*schemes.GetSubscriptionsResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubscriptions indicates an expected call of GetSubscriptions.
+func (mr *MockSubscriptionsAPIMockRecorder) GetSubscriptions(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscriptions", reflect.TypeOf((*MockSubscriptionsAPI)(nil).GetSubscriptions), ctx)
+}
+
+// Subscribe mocks base method.
+func (m *MockSubscriptionsAPI) Subscribe(ctx context.Context, subscribeURL string, updateTypes []string, secret string) (*schemes.SimpleQueryResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", ctx, subscribeURL, updateTypes, secret)
+	ret0, _ := ret[0].(*schemes.SimpleQueryResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockSubscriptionsAPIMockRecorder) Subscribe(ctx, subscribeURL, updateTypes, secret any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockSubscriptionsAPI)(nil).Subscribe), ctx, subscribeURL, updateTypes, secret)
+}
+
+// Unsubscribe mocks base method.
+func (m *MockSubscriptionsAPI) Unsubscribe(ctx context.Context, subscriptionURL string) (*schemes.SimpleQueryResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unsubscribe", ctx, subscriptionURL)
+	ret0, _ := ret[0].(*schemes.SimpleQueryResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Unsubscribe indicates an expected call of Unsubscribe.
+func (mr *MockSubscriptionsAPIMockRecorder) Unsubscribe(ctx, subscriptionURL any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unsubscribe", reflect.TypeOf((*MockSubscriptionsAPI)(nil).Unsubscribe), ctx, subscriptionURL)
+}
+
+// MockUploadsAPI is a mock of UploadsAPI interface.
+type MockUploadsAPI struct {
+	ctrl     *gomock.Controller
+	recorder *MockUploadsAPIMockRecorder
+	isgomock struct{}
+}
+
+// MockUploadsAPIMockRecorder is the mock recorder for MockUploadsAPI.
+type MockUploadsAPIMockRecorder struct {
+	mock *MockUploadsAPI
+}
+
+// NewMockUploadsAPI creates a new mock instance.
+func NewMockUploadsAPI(ctrl *gomock.Controller) *MockUploadsAPI {
+	mock := &MockUploadsAPI{ctrl: ctrl}
+	mock.recorder = &MockUploadsAPIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUploadsAPI) EXPECT() *MockUploadsAPIMockRecorder {
+	return m.recorder
+}
+
+// UploadAuto mocks base method.
+func (m *MockUploadsAPI) UploadAuto(ctx context.Context, filename string, opts ...maxbot.UploadOption) (any, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, filename}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UploadAuto", varargs...)
+	ret0, _ := ret[0].(any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UploadAuto indicates an expected call of UploadAuto.
+func (mr *MockUploadsAPIMockRecorder) UploadAuto(ctx, filename any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, filename}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadAuto", reflect.TypeOf((*MockUploadsAPI)(nil).UploadAuto), varargs...)
+}
+
+// UploadMany mocks base method.
+func (m *MockUploadsAPI) UploadMany(ctx context.Context, items []maxbot.UploadItem, opts ...maxbot.UploadOption) ([]any, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, items}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UploadMany", varargs...)
+	ret0, _ := ret[0].([]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UploadMany indicates an expected call of UploadMany.
+func (mr *MockUploadsAPIMockRecorder) UploadMany(ctx, items any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, items}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadMany", reflect.TypeOf((*MockUploadsAPI)(nil).UploadMany), varargs...)
+}
+
+// UploadMediaChunked mocks base method.
+func (m *MockUploadsAPI) UploadMediaChunked(ctx context.Context, uploadType schemes.UploadType, reader io.ReaderAt, size int64, name string, opts ...maxbot.UploadOption) (*schemes.UploadedInfo, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, uploadType, reader, size, name}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UploadMediaChunked", varargs...)
+	ret0, _ := ret[0].(*schemes.UploadedInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UploadMediaChunked indicates an expected call of UploadMediaChunked.
+func (mr *MockUploadsAPIMockRecorder) UploadMediaChunked(ctx, uploadType, reader, size, name any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, uploadType, reader, size, name}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadMediaChunked", reflect.TypeOf((*MockUploadsAPI)(nil).UploadMediaChunked), varargs...)
+}
+
+// UploadMediaFromFile mocks base method.
+func (m *MockUploadsAPI) UploadMediaFromFile(ctx context.Context, uploadType schemes.UploadType, filename string, opts ...maxbot.UploadOption) (*schemes.UploadedInfo, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, uploadType, filename}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UploadMediaFromFile", varargs...)
+	ret0, _ := ret[0].(*schemes.UploadedInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UploadMediaFromFile indicates an expected call of UploadMediaFromFile.
+func (mr *MockUploadsAPIMockRecorder) UploadMediaFromFile(ctx, uploadType, filename any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, uploadType, filename}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadMediaFromFile", reflect.TypeOf((*MockUploadsAPI)(nil).UploadMediaFromFile), varargs...)
+}
+
+// UploadMediaFromFileChunked mocks base method.
+func (m *MockUploadsAPI) UploadMediaFromFileChunked(ctx context.Context, uploadType schemes.UploadType, filename string, opts ...maxbot.UploadOption) (*schemes.UploadedInfo, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, uploadType, filename}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UploadMediaFromFileChunked", varargs...)
+	ret0, _ := ret[0].(*schemes.UploadedInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UploadMediaFromFileChunked indicates an expected call of UploadMediaFromFileChunked.
+func (mr *MockUploadsAPIMockRecorder) UploadMediaFromFileChunked(ctx, uploadType, filename any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, uploadType, filename}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadMediaFromFileChunked", reflect.TypeOf((*MockUploadsAPI)(nil).UploadMediaFromFileChunked), varargs...)
+}
+
+// UploadMediaFromReader mocks base method.
+func (m *MockUploadsAPI) UploadMediaFromReader(ctx context.Context, uploadType schemes.UploadType, reader io.Reader, opts ...maxbot.UploadOption) (*schemes.UploadedInfo, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, uploadType, reader}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UploadMediaFromReader", varargs...)
+	ret0, _ := ret[0].(*schemes.UploadedInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UploadMediaFromReader indicates an expected call of UploadMediaFromReader.
+func (mr *MockUploadsAPIMockRecorder) UploadMediaFromReader(ctx, uploadType, reader any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, uploadType, reader}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadMediaFromReader", reflect.TypeOf((*MockUploadsAPI)(nil).UploadMediaFromReader), varargs...)
+}
+
+// UploadMediaFromReaderWithName mocks base method.
+func (m *MockUploadsAPI) UploadMediaFromReaderWithName(ctx context.Context, uploadType schemes.UploadType, reader io.Reader, name string, opts ...maxbot.UploadOption) (*schemes.UploadedInfo, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, uploadType, reader, name}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UploadMediaFromReaderWithName", varargs...)
+	ret0, _ := ret[0].(*schemes.UploadedInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UploadMediaFromReaderWithName indicates an expected call of UploadMediaFromReaderWithName.
+func (mr *MockUploadsAPIMockRecorder) UploadMediaFromReaderWithName(ctx, uploadType, reader, name any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, uploadType, reader, name}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadMediaFromReaderWithName", reflect.TypeOf((*MockUploadsAPI)(nil).UploadMediaFromReaderWithName), varargs...)
+}
+
+// UploadMediaFromUrl mocks base method.
+func (m *MockUploadsAPI) UploadMediaFromUrl(ctx context.Context, uploadType schemes.UploadType, u url.URL, opts ...maxbot.UploadOption) (*schemes.UploadedInfo, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, uploadType, u}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UploadMediaFromUrl", varargs...)
+	ret0, _ := ret[0].(*schemes.UploadedInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UploadMediaFromUrl indicates an expected call of UploadMediaFromUrl.
+func (mr *MockUploadsAPIMockRecorder) UploadMediaFromUrl(ctx, uploadType, u any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, uploadType, u}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadMediaFromUrl", reflect.TypeOf((*MockUploadsAPI)(nil).UploadMediaFromUrl), varargs...)
+}
+
+// UploadPhotoFromBase64String mocks base method.
+func (m *MockUploadsAPI) UploadPhotoFromBase64String(ctx context.Context, code string, opts ...maxbot.UploadOption) (*schemes.PhotoTokens, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, code}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UploadPhotoFromBase64String", varargs...)
+	ret0, _ := ret[0].(*schemes.PhotoTokens)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UploadPhotoFromBase64String indicates an expected call of UploadPhotoFromBase64String.
+func (mr *MockUploadsAPIMockRecorder) UploadPhotoFromBase64String(ctx, code any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, code}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadPhotoFromBase64String", reflect.TypeOf((*MockUploadsAPI)(nil).UploadPhotoFromBase64String), varargs...)
+}
+
+// UploadPhotoFromFile mocks base method.
+func (m *MockUploadsAPI) UploadPhotoFromFile(ctx context.Context, fileName string, opts ...maxbot.UploadOption) (*schemes.PhotoTokens, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, fileName}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UploadPhotoFromFile", varargs...)
+	ret0, _ := ret[0].(*schemes.PhotoTokens)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UploadPhotoFromFile indicates an expected call of UploadPhotoFromFile.
+func (mr *MockUploadsAPIMockRecorder) UploadPhotoFromFile(ctx, fileName any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, fileName}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadPhotoFromFile", reflect.TypeOf((*MockUploadsAPI)(nil).UploadPhotoFromFile), varargs...)
+}
+
+// UploadPhotoFromReader mocks base method.
+func (m *MockUploadsAPI) UploadPhotoFromReader(ctx context.Context, reader io.Reader, opts ...maxbot.UploadOption) (*schemes.PhotoTokens, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, reader}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UploadPhotoFromReader", varargs...)
+	ret0, _ := ret[0].(*schemes.PhotoTokens)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UploadPhotoFromReader indicates an expected call of UploadPhotoFromReader.
+func (mr *MockUploadsAPIMockRecorder) UploadPhotoFromReader(ctx, reader any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, reader}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadPhotoFromReader", reflect.TypeOf((*MockUploadsAPI)(nil).UploadPhotoFromReader), varargs...)
+}
+
+// UploadPhotoFromReaderWithName mocks base method.
+func (m *MockUploadsAPI) UploadPhotoFromReaderWithName(ctx context.Context, reader io.Reader, name string, opts ...maxbot.UploadOption) (*schemes.PhotoTokens, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, reader, name}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UploadPhotoFromReaderWithName", varargs...)
+	ret0, _ := ret[0].(*schemes.PhotoTokens)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UploadPhotoFromReaderWithName indicates an expected call of UploadPhotoFromReaderWithName.
+func (mr *MockUploadsAPIMockRecorder) UploadPhotoFromReaderWithName(ctx, reader, name any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, reader, name}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadPhotoFromReaderWithName", reflect.TypeOf((*MockUploadsAPI)(nil).UploadPhotoFromReaderWithName), varargs...)
+}
+
+// UploadPhotoFromUrl mocks base method.
+func (m *MockUploadsAPI) UploadPhotoFromUrl(ctx context.Context, arg1 string, opts ...maxbot.UploadOption) (*schemes.PhotoTokens, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, arg1}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UploadPhotoFromUrl", varargs...)
+	ret0, _ := ret[0].(*schemes.PhotoTokens)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UploadPhotoFromUrl indicates an expected call of UploadPhotoFromUrl.
+func (mr *MockUploadsAPIMockRecorder) UploadPhotoFromUrl(ctx, arg1 any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, arg1}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadPhotoFromUrl", reflect.TypeOf((*MockUploadsAPI)(nil).UploadPhotoFromUrl), varargs...)
+}
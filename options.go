@@ -0,0 +1,172 @@
+package maxbot
+
+import "time"
+
+const (
+	defaultProgressInterval = 250 * time.Millisecond
+	defaultChunkSize        = 8 << 20
+	defaultUploadWorkers    = 4
+	defaultMaxDownloadSize  = 256 << 20
+)
+
+// UploadOption configures an upload.
+type UploadOption func(*uploadOptions)
+
+type uploadOptions struct {
+	progress         func(UploadProgress)
+	progressInterval time.Duration
+
+	cache UploadCache
+
+	chunkSize    int64
+	chunkRetries int
+	retryWait    time.Duration
+
+	workers int
+
+	skipValidation bool
+
+	image *ImageProcessing
+}
+
+func newUploadOptions(opts []UploadOption) *uploadOptions {
+	options := &uploadOptions{
+		progressInterval: defaultProgressInterval,
+		chunkSize:        defaultChunkSize,
+		chunkRetries:     maxRetries,
+		retryWait:        time.Second,
+		workers:          defaultUploadWorkers,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return options
+}
+
+// WithProgress reports the upload progress to fn. Fn is called from the uploading goroutine, so it should return quickly.
+// Cancel the context passed to the upload method to abort the transfer.
+func WithProgress(fn func(UploadProgress)) UploadOption {
+	return func(o *uploadOptions) {
+		o.progress = fn
+	}
+}
+
+// WithProgressInterval sets how often the progress is reported. The default is 250ms.
+func WithProgressInterval(interval time.Duration) UploadOption {
+	return func(o *uploadOptions) {
+		if interval > 0 {
+			o.progressInterval = interval
+		}
+	}
+}
+
+// WithUploadCache looks the content up in the cache before the upload and stores the result after it,
+// overriding the cache set by Api.SetUploadCache. Only seekable readers, e.g. files, are cached.
+func WithUploadCache(cache UploadCache) UploadOption {
+	return func(o *uploadOptions) {
+		o.cache = cache
+	}
+}
+
+// WithChunkSize sets the size of a chunk for chunked uploads. The default is 8 MB.
+func WithChunkSize(size int64) UploadOption {
+	return func(o *uploadOptions) {
+		if size > 0 {
+			o.chunkSize = size
+		}
+	}
+}
+
+// WithChunkRetries sets how many times a failed chunk is resent before the chunked upload fails,
+// and the initial pause between attempts, doubled after every attempt.
+func WithChunkRetries(retries int, wait time.Duration) UploadOption {
+	return func(o *uploadOptions) {
+		o.chunkRetries = max(retries, 0)
+		o.retryWait = wait
+	}
+}
+
+// WithImageProcessing decodes photos before the upload, downscales them and re-encodes them as JPEG,
+// which makes large scans and screenshots much smaller. It applies to photo uploads only.
+func WithImageProcessing(processing ImageProcessing) UploadOption {
+	return func(o *uploadOptions) {
+		o.image = &processing
+	}
+}
+
+// WithoutValidation sends the content as is, skipping the local checks of its type and size.
+func WithoutValidation() UploadOption {
+	return func(o *uploadOptions) {
+		o.skipValidation = true
+	}
+}
+
+// WithWorkers sets how many files UploadMany uploads at once. The default is 4.
+func WithWorkers(workers int) UploadOption {
+	return func(o *uploadOptions) {
+		if workers > 0 {
+			o.workers = workers
+		}
+	}
+}
+
+// DownloadOption configures a download.
+type DownloadOption func(*downloadOptions)
+
+type downloadOptions struct {
+	maxSize      int64
+	contentTypes []string
+}
+
+func newDownloadOptions(opts []DownloadOption) *downloadOptions {
+	options := &downloadOptions{
+		maxSize: defaultMaxDownloadSize,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return options
+}
+
+// WithMaxSize limits the size of the downloaded attachment. The default is 256 MB, zero or negative size disables the limit.
+func WithMaxSize(size int64) DownloadOption {
+	return func(o *downloadOptions) {
+		o.maxSize = size
+	}
+}
+
+// WithContentTypes sets the accepted content types of the downloaded attachment, either full ("image/png")
+// or prefixes ending with a slash ("image/"). By default the type must match the kind of the attachment.
+func WithContentTypes(types ...string) DownloadOption {
+	return func(o *downloadOptions) {
+		o.contentTypes = types
+	}
+}
+
+// ReplayOption configures a replay of the update journal.
+type ReplayOption func(*replayOptions)
+
+type replayOptions struct {
+	speed float64
+}
+
+func newReplayOptions(opts []ReplayOption) *replayOptions {
+	options := &replayOptions{
+		speed: 1,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return options
+}
+
+// WithSpeed accelerates the replay: 1 keeps the original intervals between updates, 10 makes them ten times shorter.
+// Zero or negative speed delivers updates without waiting.
+func WithSpeed(speed float64) ReplayOption {
+	return func(o *replayOptions) {
+		o.speed = speed
+	}
+}
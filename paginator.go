@@ -0,0 +1,166 @@
+package maxbot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pavmos/max-bot-api-client-go/schemes"
+)
+
+const (
+	paginatorSeparator = ":"
+	paginatorPage      = "page"
+	paginatorNoop      = "noop"
+
+	defaultPaginatorPerPage = 5
+	defaultPaginatorPrev    = "«"
+	defaultPaginatorNext    = "»"
+)
+
+// PaginatorItem is a single entry of the paginated menu.
+type PaginatorItem struct {
+	Text    string         // Visible text of the button
+	Payload string         // Payload sent back when the button is pressed
+	Intent  schemes.Intent // Intent of the button
+}
+
+// Paginator implements an inline menu that shows a long list of items page by page.
+// Current page is encoded in the callback payload of the navigation buttons, so the menu is stateless.
+type Paginator struct {
+	prefix   string
+	items    []PaginatorItem
+	perPage  int
+	columns  int
+	prevText string
+	nextText string
+}
+
+// NewPaginator returns a paginated menu. Prefix distinguishes navigation callbacks of this menu from the others.
+func NewPaginator(prefix string, items []PaginatorItem) *Paginator {
+	return &Paginator{
+		prefix:   prefix,
+		items:    items,
+		perPage:  defaultPaginatorPerPage,
+		columns:  1,
+		prevText: defaultPaginatorPrev,
+		nextText: defaultPaginatorNext,
+	}
+}
+
+// SetPerPage sets the number of items shown on one page.
+func (p *Paginator) SetPerPage(perPage int) *Paginator {
+	if perPage > 0 {
+		p.perPage = perPage
+	}
+
+	return p
+}
+
+// SetColumns sets the number of item buttons in one keyboard row.
+func (p *Paginator) SetColumns(columns int) *Paginator {
+	if columns > 0 {
+		p.columns = columns
+	}
+
+	return p
+}
+
+// SetNavigation sets the text of the previous and next page buttons.
+func (p *Paginator) SetNavigation(prev, next string) *Paginator {
+	p.prevText = prev
+	p.nextText = next
+
+	return p
+}
+
+// Pages returns the number of pages.
+func (p *Paginator) Pages() int {
+	if len(p.items) == 0 {
+		return 1
+	}
+
+	return (len(p.items) + p.perPage - 1) / p.perPage
+}
+
+// PagePayload returns the callback payload that opens the given page.
+func (p *Paginator) PagePayload(page int) string {
+	return p.prefix + paginatorSeparator + paginatorPage + paginatorSeparator + strconv.Itoa(page)
+}
+
+// noopPayload is the payload of the page counter button, which only acknowledges the callback.
+func (p *Paginator) noopPayload() string {
+	return p.prefix + paginatorSeparator + paginatorNoop
+}
+
+// ParsePayload returns the page encoded in the payload and whether the payload belongs to this menu.
+func (p *Paginator) ParsePayload(payload string) (int, bool) {
+	raw, ok := strings.CutPrefix(payload, p.prefix+paginatorSeparator+paginatorPage+paginatorSeparator)
+	if !ok {
+		return 0, false
+	}
+
+	page, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, false
+	}
+
+	return p.clamp(page), true
+}
+
+// Keyboard renders the given page: item buttons followed by a navigation row.
+func (p *Paginator) Keyboard(page int) *Keyboard {
+	page = p.clamp(page)
+	keyboard := &Keyboard{rows: make([]*KeyboardRow, 0)}
+
+	from := page * p.perPage
+	to := min(from+p.perPage, len(p.items))
+
+	var row *KeyboardRow
+	for i, item := range p.items[from:to] {
+		if i%p.columns == 0 {
+			row = keyboard.AddRow()
+		}
+		row.AddCallback(item.Text, item.Intent, item.Payload)
+	}
+
+	if p.Pages() > 1 {
+		nav := keyboard.AddRow()
+		if page > 0 {
+			nav.AddCallback(p.prevText, schemes.DEFAULT, p.PagePayload(page-1))
+		}
+		nav.AddCallback(fmt.Sprintf("%d/%d", page+1, p.Pages()), schemes.DEFAULT, p.noopPayload())
+		if page < p.Pages()-1 {
+			nav.AddCallback(p.nextText, schemes.DEFAULT, p.PagePayload(page+1))
+		}
+	}
+
+	return keyboard
+}
+
+// Message returns a message with the given text and the keyboard of the page.
+func (p *Paginator) Message(text string, page int) *Message {
+	return NewMessage().SetText(text).AddKeyboard(p.Keyboard(page))
+}
+
+// HandleCallback handles navigation callbacks of the menu by replacing the keyboard of the original message,
+// keeping its text and other attachments. The page counter button is only acknowledged.
+// It returns false if the callback does not belong to the menu, so the caller can process it further.
+func (p *Paginator) HandleCallback(ctx context.Context, messages MessagesAPI, upd *schemes.MessageCallbackUpdate) (bool, error) {
+	reply := NewCallbackReply(messages, upd)
+	if upd.Callback.Payload == p.noopPayload() {
+		return true, reply.Ack(ctx)
+	}
+
+	page, ok := p.ParsePayload(upd.Callback.Payload)
+	if !ok {
+		return false, nil
+	}
+
+	return true, reply.ReplaceKeyboard(ctx, p.Keyboard(page))
+}
+
+func (p *Paginator) clamp(page int) int {
+	return max(0, min(page, p.Pages()-1))
+}
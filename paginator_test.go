@@ -0,0 +1,177 @@
+package maxbot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavmos/max-bot-api-client-go/schemes"
+)
+
+func paginatorItems(n int) []PaginatorItem {
+	items := make([]PaginatorItem, n)
+	for i := range items {
+		items[i] = PaginatorItem{Text: "item " + strconv.Itoa(i), Payload: "item:" + strconv.Itoa(i)}
+	}
+
+	return items
+}
+
+func TestPaginator_Keyboard(t *testing.T) {
+	tests := []struct {
+		name      string
+		items     int
+		perPage   int
+		columns   int
+		page      int
+		wantPages int
+		wantItems []string
+		wantNav   []string // text=payload of the navigation buttons, nil if there is no navigation row
+	}{
+		{name: "empty", items: 0, perPage: 5, page: 0, wantPages: 1},
+		{name: "single page", items: 3, perPage: 5, page: 0, wantPages: 1, wantItems: []string{"item 0", "item 1", "item 2"}},
+		{
+			name: "first page", items: 5, perPage: 2, page: 0, wantPages: 3,
+			wantItems: []string{"item 0", "item 1"},
+			wantNav:   []string{"1/3=menu:noop", "»=menu:page:1"},
+		},
+		{
+			name: "middle page", items: 5, perPage: 2, page: 1, wantPages: 3,
+			wantItems: []string{"item 2", "item 3"},
+			wantNav:   []string{"«=menu:page:0", "2/3=menu:noop", "»=menu:page:2"},
+		},
+		{
+			name: "last page is partial", items: 5, perPage: 2, page: 2, wantPages: 3,
+			wantItems: []string{"item 4"},
+			wantNav:   []string{"«=menu:page:1", "3/3=menu:noop"},
+		},
+		{
+			name: "page above range is clamped", items: 5, perPage: 2, page: 10, wantPages: 3,
+			wantItems: []string{"item 4"},
+			wantNav:   []string{"«=menu:page:1", "3/3=menu:noop"},
+		},
+		{
+			name: "negative page is clamped", items: 5, perPage: 2, page: -1, wantPages: 3,
+			wantItems: []string{"item 0", "item 1"},
+			wantNav:   []string{"1/3=menu:noop", "»=menu:page:1"},
+		},
+		{
+			name: "columns", items: 3, perPage: 3, columns: 2, page: 0, wantPages: 1,
+			wantItems: []string{"item 0", "item 1", "item 2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			menu := NewPaginator("menu", paginatorItems(tt.items)).SetPerPage(tt.perPage).SetColumns(tt.columns)
+			require.Equal(t, tt.wantPages, menu.Pages())
+
+			rows := menu.Keyboard(tt.page).Build().Buttons
+			if tt.wantNav != nil {
+				var nav []string
+				for _, button := range rows[len(rows)-1] {
+					b := button.(schemes.CallbackButton)
+					nav = append(nav, b.Text+"="+b.Payload)
+				}
+				require.Equal(t, tt.wantNav, nav)
+				rows = rows[:len(rows)-1]
+			}
+
+			var items []string
+			for _, row := range rows {
+				require.LessOrEqual(t, len(row), max(tt.columns, 1))
+				for _, button := range row {
+					items = append(items, button.(schemes.CallbackButton).Text)
+				}
+			}
+			require.Equal(t, tt.wantItems, items)
+		})
+	}
+}
+
+func TestPaginator_ParsePayload(t *testing.T) {
+	menu := NewPaginator("menu", paginatorItems(5)).SetPerPage(2)
+
+	tests := []struct {
+		payload  string
+		wantPage int
+		wantOK   bool
+	}{
+		{payload: "menu:page:1", wantPage: 1, wantOK: true},
+		{payload: "menu:page:7", wantPage: 2, wantOK: true},
+		{payload: "menu:page:-3", wantPage: 0, wantOK: true},
+		{payload: "menu:page:x", wantOK: false},
+		{payload: "menu:noop", wantOK: false},
+		{payload: "other:page:1", wantOK: false},
+		{payload: "item:1", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.payload, func(t *testing.T) {
+			page, ok := menu.ParsePayload(tt.payload)
+			require.Equal(t, tt.wantOK, ok)
+			require.Equal(t, tt.wantPage, page)
+		})
+	}
+}
+
+func TestPaginator_HandleCallback(t *testing.T) {
+	var answers []schemes.CallbackAnswer
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		answer := schemes.CallbackAnswer{}
+		if r.URL.Path != "/answers" {
+			http.Error(w, "unexpected request", http.StatusNotFound)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&answer); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		answers = append(answers, answer)
+		json.NewEncoder(w).Encode(schemes.SimpleQueryResult{Success: true})
+	}))
+	defer server.Close()
+
+	api := newTestApi(t, server.URL)
+	menu := NewPaginator("menu", paginatorItems(5)).SetPerPage(2)
+	ctx := context.Background()
+
+	newUpdate := func(payload string) *schemes.MessageCallbackUpdate {
+		data := []byte(`{"update_type":"message_callback","timestamp":1,"callback":{"callback_id":"cb","payload":"` + payload + `"},
+			"message":{"recipient":{"chat_id":10},"body":{"mid":"mid1","text":"Menu","attachments":[
+			{"type":"image","payload":{"token":"photo-token","url":"https://example.com/photo.png"}},
+			{"type":"inline_keyboard","payload":{"buttons":[[{"type":"callback","text":"»","payload":"menu:page:1"}]]}}]}}}`)
+		update, err := api.bytesToProperUpdate(data)
+		require.NoError(t, err)
+
+		return update.(*schemes.MessageCallbackUpdate)
+	}
+
+	handled, err := menu.HandleCallback(ctx, api.Messages, newUpdate("item:1"))
+	require.NoError(t, err)
+	require.False(t, handled)
+	require.Empty(t, answers)
+
+	handled, err = menu.HandleCallback(ctx, api.Messages, newUpdate("menu:page:1"))
+	require.NoError(t, err)
+	require.True(t, handled)
+	require.Len(t, answers, 1)
+	require.Equal(t, "Menu", answers[0].Message.Text)
+	require.Len(t, answers[0].Message.Attachments, 2, "the photo is kept, the keyboard is replaced")
+	photo, err := json.Marshal(answers[0].Message.Attachments[0])
+	require.NoError(t, err)
+	require.Contains(t, string(photo), "photo-token")
+	keyboard, err := json.Marshal(answers[0].Message.Attachments[1])
+	require.NoError(t, err)
+	require.Contains(t, string(keyboard), `"text":"2/3"`)
+
+	handled, err = menu.HandleCallback(ctx, api.Messages, newUpdate("menu:noop"))
+	require.NoError(t, err)
+	require.True(t, handled)
+	require.Len(t, answers, 2)
+	require.Nil(t, answers[1].Message, "the page counter is only acknowledged")
+}
@@ -0,0 +1,129 @@
+package maxbot
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pavmos/max-bot-api-client-go/schemes"
+)
+
+const (
+	maxPayloadLength = 1024
+
+	payloadVersionPrefix = "v"
+	payloadSeparator     = "."
+	payloadSignatureSize = 12
+)
+
+var payloadEncoding = base64.RawURLEncoding
+
+// PayloadCodec serializes Go values into callback button payloads and back.
+// If a secret is set, payloads are signed with HMAC-SHA256 so that the handler can detect tampering.
+// Every payload carries a version: handlers may accept payloads of older versions during rolling deploys.
+type PayloadCodec struct {
+	secret     []byte
+	version    int
+	minVersion int
+}
+
+// NewPayloadCodec returns a codec. Pass an empty secret to produce unsigned payloads.
+func NewPayloadCodec(secret string) *PayloadCodec {
+	return &PayloadCodec{
+		secret:     []byte(secret),
+		version:    1,
+		minVersion: 1,
+	}
+}
+
+// SetVersion sets the version of encoded payloads and the oldest version accepted by Decode.
+func (c *PayloadCodec) SetVersion(version, minVersion int) *PayloadCodec {
+	c.version = version
+	c.minVersion = min(minVersion, version)
+
+	return c
+}
+
+// Encode serializes v into a payload: "v<version>.<data>[.<signature>]".
+func (c *PayloadCodec) Encode(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", &SerializationError{Op: "marshal", Type: "callback payload", Err: err}
+	}
+
+	payload := payloadVersionPrefix + strconv.Itoa(c.version) + payloadSeparator + payloadEncoding.EncodeToString(data)
+	if len(c.secret) > 0 {
+		payload += payloadSeparator + payloadEncoding.EncodeToString(c.sign(payload))
+	}
+
+	if len(payload) > maxPayloadLength {
+		return "", fmt.Errorf("%w: %d bytes", ErrPayloadTooLong, len(payload))
+	}
+
+	return payload, nil
+}
+
+// Decode verifies the payload and deserializes it into v. It returns the version the payload was encoded with,
+// so the handler can migrate values of older versions.
+func (c *PayloadCodec) Decode(payload string, v any) (int, error) {
+	parts := strings.Split(payload, payloadSeparator)
+	if len(parts) < 2 || len(parts) > 3 || !strings.HasPrefix(parts[0], payloadVersionPrefix) {
+		return 0, ErrPayloadMalformed
+	}
+
+	version, err := strconv.Atoi(strings.TrimPrefix(parts[0], payloadVersionPrefix))
+	if err != nil {
+		return 0, ErrPayloadMalformed
+	}
+	if version < c.minVersion || version > c.version {
+		return version, fmt.Errorf("%w: %d", ErrPayloadVersion, version)
+	}
+
+	if len(c.secret) > 0 {
+		if len(parts) != 3 {
+			return version, ErrPayloadSignature
+		}
+		signature, err := payloadEncoding.DecodeString(parts[2])
+		if err != nil || !hmac.Equal(signature, c.sign(parts[0]+payloadSeparator+parts[1])) {
+			return version, ErrPayloadSignature
+		}
+	}
+
+	data, err := payloadEncoding.DecodeString(parts[1])
+	if err != nil {
+		return version, ErrPayloadMalformed
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return version, &SerializationError{Op: "unmarshal", Type: "callback payload", Err: err}
+	}
+
+	return version, nil
+}
+
+// DecodeCallback decodes the payload of the pressed button.
+func (c *PayloadCodec) DecodeCallback(upd *schemes.MessageCallbackUpdate, v any) (int, error) {
+	return c.Decode(upd.Callback.Payload, v)
+}
+
+// AddCallback encodes v and adds a callback button with the payload to the row.
+func (c *PayloadCodec) AddCallback(row *KeyboardRow, text string, intent schemes.Intent, v any) error {
+	payload, err := c.Encode(v)
+	if err != nil {
+		return err
+	}
+	row.AddCallback(text, intent, payload)
+
+	return nil
+}
+
+func (c *PayloadCodec) sign(data string) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(data))
+
+	return mac.Sum(nil)[:payloadSignatureSize]
+}
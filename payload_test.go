@@ -0,0 +1,107 @@
+package maxbot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavmos/max-bot-api-client-go/schemes"
+)
+
+type testPayload struct {
+	Action string `json:"a"`
+	ID     int64  `json:"i"`
+	Page   int    `json:"p,omitempty"`
+}
+
+func TestPayloadCodec(t *testing.T) {
+	want := testPayload{Action: "ban", ID: 42, Page: 3}
+
+	tests := []struct {
+		name    string
+		encoder *PayloadCodec
+		decoder *PayloadCodec
+		tamper  func(string) string
+		err     error
+	}{
+		{
+			name:    "unsigned",
+			encoder: NewPayloadCodec(""),
+			decoder: NewPayloadCodec(""),
+		},
+		{
+			name:    "signed",
+			encoder: NewPayloadCodec("secret"),
+			decoder: NewPayloadCodec("secret"),
+		},
+		{
+			name:    "wrong secret",
+			encoder: NewPayloadCodec("secret"),
+			decoder: NewPayloadCodec("other"),
+			err:     ErrPayloadSignature,
+		},
+		{
+			name:    "missing signature",
+			encoder: NewPayloadCodec(""),
+			decoder: NewPayloadCodec("secret"),
+			err:     ErrPayloadSignature,
+		},
+		{
+			name:    "tampered data",
+			encoder: NewPayloadCodec("secret"),
+			decoder: NewPayloadCodec("secret"),
+			tamper: func(p string) string {
+				parts := strings.Split(p, ".")
+				parts[1] = payloadEncoding.EncodeToString([]byte(`{"a":"ban","i":1}`))
+				return strings.Join(parts, ".")
+			},
+			err: ErrPayloadSignature,
+		},
+		{
+			name:    "old version accepted",
+			encoder: NewPayloadCodec("secret").SetVersion(1, 1),
+			decoder: NewPayloadCodec("secret").SetVersion(2, 1),
+		},
+		{
+			name:    "old version rejected",
+			encoder: NewPayloadCodec("secret").SetVersion(1, 1),
+			decoder: NewPayloadCodec("secret").SetVersion(3, 2),
+			err:     ErrPayloadVersion,
+		},
+		{
+			name:    "malformed",
+			encoder: NewPayloadCodec(""),
+			decoder: NewPayloadCodec(""),
+			tamper:  func(string) string { return "orders:page:1" },
+			err:     ErrPayloadMalformed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, err := tt.encoder.Encode(want)
+			require.NoError(t, err)
+			if tt.tamper != nil {
+				payload = tt.tamper(payload)
+			}
+
+			upd := &schemes.MessageCallbackUpdate{Callback: schemes.Callback{Payload: payload}}
+
+			var got testPayload
+			_, err = tt.decoder.DecodeCallback(upd, &got)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, want, got)
+		})
+	}
+}
+
+func TestPayloadCodec_TooLong(t *testing.T) {
+	_, err := NewPayloadCodec("secret").Encode(strings.Repeat("x", maxPayloadLength))
+	require.ErrorIs(t, err, ErrPayloadTooLong)
+}
@@ -0,0 +1,121 @@
+package maxbot
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+const throughputSmoothing = 0.3
+
+// UploadProgress describes the state of an upload.
+type UploadProgress struct {
+	Sent       int64         // Bytes of the file sent so far
+	Total      int64         // Size of the file, -1 if unknown
+	Elapsed    time.Duration // Time since the upload has started
+	Throughput float64       // Estimated throughput in bytes per second
+	Done       bool          // True for the last report, when the server has accepted the upload
+}
+
+// Percent returns the share of the file sent in percent, or -1 if the size is unknown.
+func (p UploadProgress) Percent() float64 {
+	if p.Total <= 0 {
+		return -1
+	}
+
+	return float64(p.Sent) * 100 / float64(p.Total)
+}
+
+// Remaining estimates the time left to send the file, or 0 if it cannot be estimated.
+func (p UploadProgress) Remaining() time.Duration {
+	if p.Total < 0 || p.Throughput <= 0 {
+		return 0
+	}
+
+	return time.Duration(float64(p.Total-p.Sent) / p.Throughput * float64(time.Second))
+}
+
+// progressTracker reports the progress with throughput smoothed over the reports.
+type progressTracker struct {
+	options    *uploadOptions
+	started    time.Time
+	reported   time.Time
+	sentAtLast int64
+	progress   UploadProgress
+}
+
+func newProgressTracker(total int64, options *uploadOptions) *progressTracker {
+	now := time.Now()
+
+	return &progressTracker{
+		options:  options,
+		started:  now,
+		reported: now,
+		progress: UploadProgress{Total: total},
+	}
+}
+
+// update reports the progress if the interval has passed since the last report or the upload is done.
+func (t *progressTracker) update(sent int64, done bool) {
+	if t.options.progress == nil {
+		return
+	}
+
+	now := time.Now()
+	if !done && now.Sub(t.reported) < t.options.progressInterval {
+		return
+	}
+
+	if interval := now.Sub(t.reported).Seconds(); interval > 0 {
+		rate := float64(sent-t.sentAtLast) / interval
+		if t.progress.Throughput == 0 {
+			t.progress.Throughput = rate
+		} else {
+			t.progress.Throughput = throughputSmoothing*rate + (1-throughputSmoothing)*t.progress.Throughput
+		}
+	}
+
+	t.reported = now
+	t.sentAtLast = sent
+	t.progress.Sent = sent
+	t.progress.Elapsed = now.Sub(t.started)
+	t.progress.Done = done
+	if done && t.progress.Total < 0 {
+		t.progress.Total = sent
+	}
+
+	t.options.progress(t.progress)
+}
+
+// progressReader counts bytes read from the file and reports the progress. It stops reading once the context is done.
+type progressReader struct {
+	ctx     context.Context
+	reader  io.Reader
+	tracker *progressTracker
+	sent    int64
+}
+
+func newProgressReader(ctx context.Context, reader io.Reader, total int64, options *uploadOptions) *progressReader {
+	return &progressReader{
+		ctx:     ctx,
+		reader:  reader,
+		tracker: newProgressTracker(total, options),
+	}
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	n, err := r.reader.Read(p)
+	r.sent += int64(n)
+	r.tracker.update(r.sent, false)
+
+	return n, err
+}
+
+// done reports the final progress once the server has accepted the upload.
+func (r *progressReader) done() {
+	r.tracker.update(r.sent, true)
+}
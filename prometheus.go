@@ -0,0 +1,261 @@
+package maxbot
+
+import (
+	"bufio"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pavmos/max-bot-api-client-go/schemes"
+)
+
+const prometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+var (
+	latencyBuckets   = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+	pollBuckets      = []float64{0.1, 0.5, 1, 5, 10, 30, 60, 90}
+	batchSizeBuckets = []float64{0, 1, 5, 10, 25, 50, 100, 250, 500, 1000}
+)
+
+// PrometheusMetrics collects measurements and serves them in the Prometheus text format:
+//
+//	maxbot_requests_total{method,path,status}, maxbot_request_duration_seconds{method,path}
+//	maxbot_polls_total, maxbot_poll_errors_total, maxbot_poll_duration_seconds, maxbot_poll_updates
+//	maxbot_updates_total{type}, maxbot_update_parse_failures_total{type}
+//	maxbot_handler_calls_total{handler}, maxbot_handler_errors_total{handler}, maxbot_handler_duration_seconds{handler}
+//
+// Use WithBot to collect metrics of several bots in one handler.
+type PrometheusMetrics struct {
+	registry *prometheusRegistry
+	labels   []string
+}
+
+// NewPrometheusMetrics returns empty metrics.
+func NewPrometheusMetrics() *PrometheusMetrics {
+	return &PrometheusMetrics{registry: newPrometheusRegistry()}
+}
+
+// WithBot returns metrics sharing the collected series and the handler, with the label bot="name" added to every series.
+func (m *PrometheusMetrics) WithBot(name string) *PrometheusMetrics {
+	return &PrometheusMetrics{registry: m.registry, labels: append(slices.Clip(m.labels), "bot", name)}
+}
+
+// ObserveRequest implements Metrics.
+func (m *PrometheusMetrics) ObserveRequest(method, path string, status int, latency time.Duration) {
+	m.registry.add("maxbot_requests_total", m.with("method", method, "path", path, "status", strconv.Itoa(status)), 1)
+	m.registry.observe("maxbot_request_duration_seconds", m.with("method", method, "path", path), latency.Seconds())
+}
+
+// ObservePoll implements Metrics.
+func (m *PrometheusMetrics) ObservePoll(latency time.Duration, updates int, err error) {
+	labels := m.with()
+	m.registry.add("maxbot_polls_total", labels, 1)
+	if err != nil {
+		m.registry.add("maxbot_poll_errors_total", labels, 1)
+	}
+	m.registry.observe("maxbot_poll_duration_seconds", labels, latency.Seconds())
+	m.registry.observe("maxbot_poll_updates", labels, float64(updates))
+}
+
+// ObserveUpdate implements Metrics.
+func (m *PrometheusMetrics) ObserveUpdate(updateType schemes.UpdateType, err error) {
+	typ := string(updateType)
+	if typ == "" {
+		typ = unknownUpdateType
+	}
+	if err != nil {
+		m.registry.add("maxbot_update_parse_failures_total", m.with("type", typ), 1)
+		return
+	}
+	m.registry.add("maxbot_updates_total", m.with("type", typ), 1)
+}
+
+// ObserveHandler implements Metrics.
+func (m *PrometheusMetrics) ObserveHandler(handler string, latency time.Duration, err error) {
+	labels := m.with("handler", handler)
+	m.registry.add("maxbot_handler_calls_total", labels, 1)
+	if err != nil {
+		m.registry.add("maxbot_handler_errors_total", labels, 1)
+	}
+	m.registry.observe("maxbot_handler_duration_seconds", labels, latency.Seconds())
+}
+
+// ServeHTTP writes all collected series in the Prometheus text format.
+func (m *PrometheusMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", prometheusContentType)
+
+	bw := bufio.NewWriter(w)
+	m.registry.write(bw)
+	_ = bw.Flush()
+}
+
+// with renders the label pairs after the labels of the bot.
+func (m *PrometheusMetrics) with(pairs ...string) string {
+	pairs = append(slices.Clip(m.labels), pairs...)
+
+	var sb strings.Builder
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(pairs[i])
+		sb.WriteString(`="`)
+		sb.WriteString(escapeLabelValue(pairs[i+1]))
+		sb.WriteByte('"')
+	}
+
+	return sb.String()
+}
+
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+type prometheusFamily struct {
+	name    string
+	help    string
+	buckets []float64 // nil for counters
+	series  map[string]*prometheusSeries
+}
+
+type prometheusSeries struct {
+	value  float64  // value of a counter, sum of a histogram
+	counts []uint64 // observations per bucket of a histogram, not cumulative
+	count  uint64
+}
+
+type prometheusRegistry struct {
+	mu       sync.Mutex
+	families []*prometheusFamily
+}
+
+func newPrometheusRegistry() *prometheusRegistry {
+	counter := func(name, help string) *prometheusFamily {
+		return &prometheusFamily{name: name, help: help, series: make(map[string]*prometheusSeries)}
+	}
+	histogram := func(name, help string, buckets []float64) *prometheusFamily {
+		return &prometheusFamily{name: name, help: help, buckets: buckets, series: make(map[string]*prometheusSeries)}
+	}
+
+	return &prometheusRegistry{families: []*prometheusFamily{
+		counter("maxbot_requests_total", "Requests to the MAX Bot API."),
+		histogram("maxbot_request_duration_seconds", "Latency of requests to the MAX Bot API.", latencyBuckets),
+		counter("maxbot_polls_total", "Long polling requests for updates."),
+		counter("maxbot_poll_errors_total", "Failed long polling requests for updates."),
+		histogram("maxbot_poll_duration_seconds", "Latency of long polling requests for updates.", pollBuckets),
+		histogram("maxbot_poll_updates", "Updates received by a long polling request.", batchSizeBuckets),
+		counter("maxbot_updates_total", "Received updates."),
+		counter("maxbot_update_parse_failures_total", "Received updates that failed to parse."),
+		counter("maxbot_handler_calls_total", "Calls of update handlers."),
+		counter("maxbot_handler_errors_total", "Calls of update handlers that returned an error."),
+		histogram("maxbot_handler_duration_seconds", "Latency of update handlers.", latencyBuckets),
+	}}
+}
+
+func (r *prometheusRegistry) family(name string) *prometheusFamily {
+	i := slices.IndexFunc(r.families, func(f *prometheusFamily) bool { return f.name == name })
+
+	return r.families[i]
+}
+
+func (r *prometheusRegistry) series(name, labels string) (*prometheusFamily, *prometheusSeries) {
+	f := r.family(name)
+	s, ok := f.series[labels]
+	if !ok {
+		s = &prometheusSeries{counts: make([]uint64, len(f.buckets))}
+		f.series[labels] = s
+	}
+
+	return f, s
+}
+
+func (r *prometheusRegistry) add(name, labels string, value float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, s := r.series(name, labels)
+	s.value += value
+}
+
+func (r *prometheusRegistry) observe(name, labels string, value float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, s := r.series(name, labels)
+	if i, _ := slices.BinarySearch(f.buckets, value); i < len(f.buckets) {
+		s.counts[i]++
+	}
+	s.value += value
+	s.count++
+}
+
+// write writes the families in the order of declaration and their series sorted by labels.
+// Families without series are skipped.
+func (r *prometheusRegistry) write(w *bufio.Writer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, f := range r.families {
+		if len(f.series) == 0 {
+			continue
+		}
+
+		kind := "counter"
+		if f.buckets != nil {
+			kind = "histogram"
+		}
+		w.WriteString("# HELP " + f.name + " " + f.help + "\n")
+		w.WriteString("# TYPE " + f.name + " " + kind + "\n")
+
+		labelSets := make([]string, 0, len(f.series))
+		for labels := range f.series {
+			labelSets = append(labelSets, labels)
+		}
+		slices.Sort(labelSets)
+
+		for _, labels := range labelSets {
+			s := f.series[labels]
+			if f.buckets == nil {
+				writeSample(w, f.name, labels, s.value)
+				continue
+			}
+
+			var cumulative uint64
+			for i, bound := range f.buckets {
+				cumulative += s.counts[i]
+				writeSample(w, f.name+"_bucket", joinLabels(labels, `le="`+formatFloat(bound)+`"`), float64(cumulative))
+			}
+			writeSample(w, f.name+"_bucket", joinLabels(labels, `le="+Inf"`), float64(s.count))
+			writeSample(w, f.name+"_sum", labels, s.value)
+			writeSample(w, f.name+"_count", labels, float64(s.count))
+		}
+	}
+}
+
+func writeSample(w *bufio.Writer, name, labels string, value float64) {
+	w.WriteString(name)
+	if labels != "" {
+		w.WriteString("{" + labels + "}")
+	}
+	w.WriteString(" " + formatFloat(value) + "\n")
+}
+
+func joinLabels(labels, label string) string {
+	if labels == "" {
+		return label
+	}
+
+	return labels + "," + label
+}
+
+func formatFloat(value float64) string {
+	if math.IsInf(value, 1) {
+		return "+Inf"
+	}
+
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
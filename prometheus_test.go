@@ -0,0 +1,107 @@
+package maxbot
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavmos/max-bot-api-client-go/schemes"
+)
+
+func TestPrometheusMetrics(t *testing.T) {
+	metrics := NewPrometheusMetrics()
+	news := metrics.WithBot("news")
+	support := metrics.WithBot(`support "eu"`)
+
+	news.ObserveRequest(http.MethodGet, "chats/{chat_id}", http.StatusOK, 20*time.Millisecond)
+	news.ObserveRequest(http.MethodGet, "chats/{chat_id}", http.StatusNotFound, 300*time.Millisecond)
+	support.ObservePoll(2*time.Second, 5, nil)
+	support.ObservePoll(30*time.Second, 0, errors.New("timeout"))
+	support.ObserveUpdate(schemes.TypeMessageCreated, nil)
+	support.ObserveUpdate("", errors.New("invalid"))
+
+	w := httptest.NewRecorder()
+	metrics.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, prometheusContentType, w.Header().Get("Content-Type"))
+
+	want := strings.Join([]string{
+		`# HELP maxbot_requests_total Requests to the MAX Bot API.`,
+		`# TYPE maxbot_requests_total counter`,
+		`maxbot_requests_total{bot="news",method="GET",path="chats/{chat_id}",status="200"} 1`,
+		`maxbot_requests_total{bot="news",method="GET",path="chats/{chat_id}",status="404"} 1`,
+		`# HELP maxbot_request_duration_seconds Latency of requests to the MAX Bot API.`,
+		`# TYPE maxbot_request_duration_seconds histogram`,
+		`maxbot_request_duration_seconds_bucket{bot="news",method="GET",path="chats/{chat_id}",le="0.005"} 0`,
+		`maxbot_request_duration_seconds_bucket{bot="news",method="GET",path="chats/{chat_id}",le="0.01"} 0`,
+		`maxbot_request_duration_seconds_bucket{bot="news",method="GET",path="chats/{chat_id}",le="0.025"} 1`,
+		`maxbot_request_duration_seconds_bucket{bot="news",method="GET",path="chats/{chat_id}",le="0.05"} 1`,
+		`maxbot_request_duration_seconds_bucket{bot="news",method="GET",path="chats/{chat_id}",le="0.1"} 1`,
+		`maxbot_request_duration_seconds_bucket{bot="news",method="GET",path="chats/{chat_id}",le="0.25"} 1`,
+		`maxbot_request_duration_seconds_bucket{bot="news",method="GET",path="chats/{chat_id}",le="0.5"} 2`,
+		`maxbot_request_duration_seconds_bucket{bot="news",method="GET",path="chats/{chat_id}",le="1"} 2`,
+		`maxbot_request_duration_seconds_bucket{bot="news",method="GET",path="chats/{chat_id}",le="2.5"} 2`,
+		`maxbot_request_duration_seconds_bucket{bot="news",method="GET",path="chats/{chat_id}",le="5"} 2`,
+		`maxbot_request_duration_seconds_bucket{bot="news",method="GET",path="chats/{chat_id}",le="10"} 2`,
+		`maxbot_request_duration_seconds_bucket{bot="news",method="GET",path="chats/{chat_id}",le="+Inf"} 2`,
+		`maxbot_request_duration_seconds_sum{bot="news",method="GET",path="chats/{chat_id}"} 0.32`,
+		`maxbot_request_duration_seconds_count{bot="news",method="GET",path="chats/{chat_id}"} 2`,
+		`# HELP maxbot_polls_total Long polling requests for updates.`,
+		`# TYPE maxbot_polls_total counter`,
+		`maxbot_polls_total{bot="support \"eu\""} 2`,
+		`# HELP maxbot_poll_errors_total Failed long polling requests for updates.`,
+		`# TYPE maxbot_poll_errors_total counter`,
+		`maxbot_poll_errors_total{bot="support \"eu\""} 1`,
+		`# HELP maxbot_poll_duration_seconds Latency of long polling requests for updates.`,
+		`# TYPE maxbot_poll_duration_seconds histogram`,
+		`maxbot_poll_duration_seconds_bucket{bot="support \"eu\"",le="0.1"} 0`,
+		`maxbot_poll_duration_seconds_bucket{bot="support \"eu\"",le="0.5"} 0`,
+		`maxbot_poll_duration_seconds_bucket{bot="support \"eu\"",le="1"} 0`,
+		`maxbot_poll_duration_seconds_bucket{bot="support \"eu\"",le="5"} 1`,
+		`maxbot_poll_duration_seconds_bucket{bot="support \"eu\"",le="10"} 1`,
+		`maxbot_poll_duration_seconds_bucket{bot="support \"eu\"",le="30"} 2`,
+		`maxbot_poll_duration_seconds_bucket{bot="support \"eu\"",le="60"} 2`,
+		`maxbot_poll_duration_seconds_bucket{bot="support \"eu\"",le="90"} 2`,
+		`maxbot_poll_duration_seconds_bucket{bot="support \"eu\"",le="+Inf"} 2`,
+		`maxbot_poll_duration_seconds_sum{bot="support \"eu\""} 32`,
+		`maxbot_poll_duration_seconds_count{bot="support \"eu\""} 2`,
+		`# HELP maxbot_poll_updates Updates received by a long polling request.`,
+		`# TYPE maxbot_poll_updates histogram`,
+		`maxbot_poll_updates_bucket{bot="support \"eu\"",le="0"} 1`,
+		`maxbot_poll_updates_bucket{bot="support \"eu\"",le="1"} 1`,
+		`maxbot_poll_updates_bucket{bot="support \"eu\"",le="5"} 2`,
+		`maxbot_poll_updates_bucket{bot="support \"eu\"",le="10"} 2`,
+		`maxbot_poll_updates_bucket{bot="support \"eu\"",le="25"} 2`,
+		`maxbot_poll_updates_bucket{bot="support \"eu\"",le="50"} 2`,
+		`maxbot_poll_updates_bucket{bot="support \"eu\"",le="100"} 2`,
+		`maxbot_poll_updates_bucket{bot="support \"eu\"",le="250"} 2`,
+		`maxbot_poll_updates_bucket{bot="support \"eu\"",le="500"} 2`,
+		`maxbot_poll_updates_bucket{bot="support \"eu\"",le="1000"} 2`,
+		`maxbot_poll_updates_bucket{bot="support \"eu\"",le="+Inf"} 2`,
+		`maxbot_poll_updates_sum{bot="support \"eu\""} 5`,
+		`maxbot_poll_updates_count{bot="support \"eu\""} 2`,
+		`# HELP maxbot_updates_total Received updates.`,
+		`# TYPE maxbot_updates_total counter`,
+		`maxbot_updates_total{bot="support \"eu\"",type="message_created"} 1`,
+		`# HELP maxbot_update_parse_failures_total Received updates that failed to parse.`,
+		`# TYPE maxbot_update_parse_failures_total counter`,
+		`maxbot_update_parse_failures_total{bot="support \"eu\"",type="unknown"} 1`,
+	}, "\n") + "\n"
+	require.Equal(t, want, w.Body.String())
+}
+
+func TestPrometheusMetrics_Handlers(t *testing.T) {
+	metrics := NewPrometheusMetrics()
+	metrics.ObserveHandler("/start", 15*time.Second, errors.New("failed"))
+
+	w := httptest.NewRecorder()
+	metrics.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Contains(t, w.Body.String(), "maxbot_handler_calls_total{handler=\"/start\"} 1\n")
+	require.Contains(t, w.Body.String(), "maxbot_handler_errors_total{handler=\"/start\"} 1\n")
+	require.Contains(t, w.Body.String(), "maxbot_handler_duration_seconds_bucket{handler=\"/start\",le=\"10\"} 0\n")
+	require.Contains(t, w.Body.String(), "maxbot_handler_duration_seconds_bucket{handler=\"/start\",le=\"+Inf\"} 1\n")
+}
@@ -0,0 +1,278 @@
+package maxbot
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/pavmos/max-bot-api-client-go/schemes"
+)
+
+const (
+	helpCommand            = "help"
+	defaultHelpDescription = "Список команд"
+	defaultErrorReply      = "Неверные аргументы команды. Использование:"
+)
+
+// CommandHandler handles a command. Args points to a new value of the command argument schema
+// filled from the command, or is nil if the command has no schema.
+type CommandHandler func(ctx context.Context, upd *schemes.MessageCreatedUpdate, cmd *Command, args any) error
+
+// CommandSpec declares a bot command.
+type CommandSpec struct {
+	Name        string         // Command name without the leading slash
+	Description string         // Description shown in the bot command list and in /help
+	Args        any            // Argument schema: a struct value, see Command.Bind
+	Handler     CommandHandler // Command handler, nil to declare the command and process it outside the registry
+	Hidden      bool           // If true, the command is not published in the bot command list and /help
+}
+
+// CommandRegistry keeps commands declared in code, publishes them to the bot command list and dispatches updates to the handlers.
+// A /help command listing all commands is added unless registered explicitly, its description is "Список команд"
+// unless set by SetHelpDescription. Malformed arguments are answered with the command usage, see SetErrorReply.
+type CommandRegistry struct {
+	bots     BotsAPI
+	messages MessagesAPI
+	client   *client
+
+	mu              sync.RWMutex
+	specs           []CommandSpec
+	username        string
+	helpDescription string
+	errorReply      func(spec CommandSpec, err error) string
+}
+
+// NewCommandRegistry returns an empty command registry.
+func (a *Api) NewCommandRegistry() *CommandRegistry {
+	return &CommandRegistry{
+		bots:            a.Bots,
+		messages:        a.Messages,
+		client:          a.client,
+		helpDescription: defaultHelpDescription,
+		errorReply:      defaultCommandErrorReply,
+	}
+}
+
+// SetHelpDescription sets the description of the default /help command.
+func (r *CommandRegistry) SetHelpDescription(description string) *CommandRegistry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.helpDescription = description
+
+	return r
+}
+
+// SetErrorReply sets the function returning the reply to a registry command with malformed arguments.
+// Err wraps ErrCommandSyntax or ErrCommandArgs. By default the reply is "Неверные аргументы команды. Использование:"
+// followed by the command usage, see CommandUsage. Nil restores the default.
+func (r *CommandRegistry) SetErrorReply(reply func(spec CommandSpec, err error) string) *CommandRegistry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if reply == nil {
+		reply = defaultCommandErrorReply
+	}
+	r.errorReply = reply
+
+	return r
+}
+
+// Register adds the command. A command with the same name is replaced.
+func (r *CommandRegistry) Register(spec CommandSpec) *CommandRegistry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	spec.Name = strings.TrimPrefix(spec.Name, commandPrefix)
+	if i := r.index(spec.Name); i >= 0 {
+		r.specs[i] = spec
+	} else {
+		r.specs = append(r.specs, spec)
+	}
+
+	return r
+}
+
+// BotCommands returns the published commands in the order of registration.
+func (r *CommandRegistry) BotCommands() []schemes.BotCommand {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	commands := make([]schemes.BotCommand, 0, len(r.specs)+1)
+	for _, spec := range r.withHelp() {
+		if !spec.Hidden {
+			commands = append(commands, schemes.BotCommand{Name: spec.Name, Description: spec.Description})
+		}
+	}
+
+	return commands
+}
+
+// Sync compares the registry with the bot command list and patches the bot if they differ.
+// It returns true if the bot has been patched.
+func (r *CommandRegistry) Sync(ctx context.Context) (bool, error) {
+	info, err := r.bots.GetBot(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.Lock()
+	r.username = info.Username
+	r.mu.Unlock()
+
+	commands := r.BotCommands()
+	if slices.Equal(info.Commands, commands) {
+		return false, nil
+	}
+
+	if _, err := r.bots.PatchBot(ctx, &schemes.BotPatch{Commands: commands}); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Help returns the text of the /help response.
+func (r *CommandRegistry) Help() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var sb strings.Builder
+	for _, spec := range r.withHelp() {
+		if spec.Hidden {
+			continue
+		}
+		sb.WriteString(CommandUsage(spec))
+		if spec.Description != "" {
+			sb.WriteString(" — " + spec.Description)
+		}
+		sb.WriteString("\n")
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// Handle dispatches the command in the message to its handler. It returns false if the message is not a command
+// of the registry or the command has no handler, so the caller can process it further.
+// Malformed arguments of the registry commands are answered with the text of SetErrorReply.
+func (r *CommandRegistry) Handle(ctx context.Context, upd *schemes.MessageCreatedUpdate) (bool, error) {
+	r.mu.RLock()
+	parser := NewCommandParser(r.username, nil)
+	specs := r.withHelp()
+	errorReply := r.errorReply
+	r.mu.RUnlock()
+
+	head, err := parseCommandHead(upd.GetText())
+	if err != nil || !parser.addressed(head) {
+		return false, nil
+	}
+
+	i := slices.IndexFunc(specs, func(s CommandSpec) bool { return strings.EqualFold(s.Name, head.Name) })
+	if i < 0 {
+		return false, nil
+	}
+	spec := specs[i]
+
+	if spec.Handler == nil {
+		if strings.EqualFold(spec.Name, helpCommand) {
+			return true, r.reply(ctx, upd, r.Help())
+		}
+		return false, nil
+	}
+
+	cmd, err := parser.ParseUpdate(upd)
+	if err != nil {
+		return true, r.reply(ctx, upd, errorReply(spec, err))
+	}
+
+	var args any
+	if rt := argsType(spec.Args); rt != nil {
+		args = reflect.New(rt).Interface()
+		if err := cmd.Bind(args); err != nil {
+			return true, r.reply(ctx, upd, errorReply(spec, err))
+		}
+	}
+
+	return true, r.client.observeHandler(commandPrefix+spec.Name, func() error {
+		return spec.Handler(ctx, upd, cmd, args)
+	})
+}
+
+// withHelp returns the commands with the default /help appended if it is not registered.
+func (r *CommandRegistry) withHelp() []CommandSpec {
+	if r.index(helpCommand) >= 0 {
+		return r.specs
+	}
+
+	return append(slices.Clip(r.specs), CommandSpec{Name: helpCommand, Description: r.helpDescription})
+}
+
+func (r *CommandRegistry) index(name string) int {
+	return slices.IndexFunc(r.specs, func(s CommandSpec) bool { return strings.EqualFold(s.Name, name) })
+}
+
+func (r *CommandRegistry) reply(ctx context.Context, upd *schemes.MessageCreatedUpdate, text string) error {
+	return r.messages.Send(ctx, NewMessage().Reply(text, upd.Message))
+}
+
+// CommandUsage returns the command with its argument schema: /name <required> [optional] [rest...]
+func CommandUsage(spec CommandSpec) string {
+	usage := commandPrefix + strings.TrimPrefix(spec.Name, commandPrefix)
+	if args := argsUsage(spec.Args); args != "" {
+		usage += " " + args
+	}
+
+	return usage
+}
+
+func defaultCommandErrorReply(spec CommandSpec, _ error) string {
+	return defaultErrorReply + "\n" + CommandUsage(spec)
+}
+
+// argsUsage describes the argument schema: <required> [optional] [rest...]
+func argsUsage(schema any) string {
+	rt := argsType(schema)
+	if rt == nil {
+		return ""
+	}
+
+	usage := make([]string, 0, rt.NumField())
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		tag := field.Tag.Get(tagArg)
+		if !field.IsExported() || tag == tagArgSkip {
+			continue
+		}
+
+		name := strings.ToLower(field.Name)
+		switch tag {
+		case tagArgRest:
+			usage = append(usage, "["+name+"...]")
+		case tagArgOptional:
+			usage = append(usage, "["+name+"]")
+		default:
+			usage = append(usage, "<"+name+">")
+		}
+	}
+
+	return strings.Join(usage, " ")
+}
+
+// argsType returns the struct type of the argument schema, or nil if there is no schema.
+func argsType(schema any) reflect.Type {
+	if schema == nil {
+		return nil
+	}
+
+	rt := reflect.TypeOf(schema)
+	if rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	if rt.Kind() != reflect.Struct {
+		return nil
+	}
+
+	return rt
+}
@@ -0,0 +1,148 @@
+package maxbot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavmos/max-bot-api-client-go/schemes"
+)
+
+func TestCommandRegistry(t *testing.T) {
+	var patched *schemes.BotPatch
+	var sent []schemes.NewMessageBody
+
+	// The handler only records the requests: require must not be called outside the test goroutine.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/me":
+			json.NewEncoder(w).Encode(schemes.BotInfo{
+				Username: "mybot",
+				Commands: []schemes.BotCommand{{Name: "start", Description: "old"}},
+			})
+		case r.Method == http.MethodPatch && r.URL.Path == "/me":
+			patched = new(schemes.BotPatch)
+			if err := json.NewDecoder(r.Body).Decode(patched); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			json.NewEncoder(w).Encode(schemes.BotInfo{Username: "mybot", Commands: patched.Commands})
+		case r.Method == http.MethodPost && r.URL.Path == "/messages":
+			body := schemes.NewMessageBody{}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			sent = append(sent, body)
+			json.NewEncoder(w).Encode(MessageResponse{})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	api := newTestApi(t, server.URL)
+
+	type banArgs struct {
+		User     string
+		Duration string `arg:"optional"`
+	}
+
+	var banned banArgs
+	registry := api.NewCommandRegistry().
+		Register(CommandSpec{Name: "start", Description: "Начать"}).
+		Register(CommandSpec{
+			Name:        "ban",
+			Description: "Заблокировать",
+			Args:        banArgs{},
+			Handler: func(ctx context.Context, upd *schemes.MessageCreatedUpdate, cmd *Command, args any) error {
+				banned = *args.(*banArgs)
+				return nil
+			},
+		})
+
+	ok, err := registry.Sync(context.Background())
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, []schemes.BotCommand{
+		{Name: "start", Description: "Начать"},
+		{Name: "ban", Description: "Заблокировать"},
+		{Name: "help", Description: defaultHelpDescription},
+	}, patched.Commands)
+	require.Equal(t, "/start — Начать\n/ban <user> [duration] — Заблокировать\n/help — "+defaultHelpDescription, registry.Help())
+
+	message := func(text string) *schemes.MessageCreatedUpdate {
+		return &schemes.MessageCreatedUpdate{Message: schemes.Message{
+			Recipient: schemes.Recipient{ChatId: 1},
+			Body:      schemes.MessageBody{Mid: "mid", Text: text},
+		}}
+	}
+
+	handled, err := registry.Handle(context.Background(), message("/ban@mybot @user 1h"))
+	require.NoError(t, err)
+	require.True(t, handled)
+	require.Equal(t, banArgs{User: "@user", Duration: "1h"}, banned)
+
+	handled, err = registry.Handle(context.Background(), message("/ban@otherbot @user"))
+	require.NoError(t, err)
+	require.False(t, handled)
+
+	// Commands without a handler are left to the caller.
+	handled, err = registry.Handle(context.Background(), message("/start"))
+	require.NoError(t, err)
+	require.False(t, handled)
+	require.Empty(t, sent)
+
+	handled, err = registry.Handle(context.Background(), message("/help"))
+	require.NoError(t, err)
+	require.True(t, handled)
+	require.Len(t, sent, 1)
+	require.Equal(t, registry.Help(), sent[0].Text)
+
+	handled, err = registry.Handle(context.Background(), message("/ban"))
+	require.NoError(t, err)
+	require.True(t, handled)
+	require.Len(t, sent, 2)
+	require.Equal(t, defaultErrorReply+"\n/ban <user> [duration]", sent[1].Text)
+
+	handled, err = registry.Handle(context.Background(), message(`/ban "@user`))
+	require.NoError(t, err)
+	require.True(t, handled)
+	require.Len(t, sent, 3)
+	require.Equal(t, defaultErrorReply+"\n/ban <user> [duration]", sent[2].Text)
+
+	// Malformed commands of other handlers are left to the caller.
+	handled, err = registry.Handle(context.Background(), message(`/other "x`))
+	require.NoError(t, err)
+	require.False(t, handled)
+	handled, err = registry.Handle(context.Background(), message(`/ban@otherbot "x`))
+	require.NoError(t, err)
+	require.False(t, handled)
+	require.Len(t, sent, 3)
+
+	registry.SetErrorReply(func(spec CommandSpec, err error) string {
+		return "Usage: " + CommandUsage(spec) + " (" + err.Error() + ")"
+	})
+	handled, err = registry.Handle(context.Background(), message("/ban"))
+	require.NoError(t, err)
+	require.True(t, handled)
+	require.Equal(t, "Usage: /ban <user> [duration] (invalid command arguments: missing value for user)", sent[3].Text)
+}
+
+func TestCommandRegistry_HelpDescription(t *testing.T) {
+	api, err := New("test")
+	require.NoError(t, err)
+
+	registry := api.NewCommandRegistry().
+		Register(CommandSpec{Name: "start", Description: "Start"}).
+		SetHelpDescription("Commands")
+	require.Equal(t, []schemes.BotCommand{
+		{Name: "start", Description: "Start"},
+		{Name: "help", Description: "Commands"},
+	}, registry.BotCommands())
+	require.Equal(t, "/start — Start\n/help — Commands", registry.Help())
+}
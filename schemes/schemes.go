@@ -332,6 +332,67 @@ type Keyboard struct {
 	Buttons [][]ButtonInterface `json:"buttons"`
 }
 
+// UnmarshalJSON decodes every button into the concrete type according to its type.
+func (k *Keyboard) UnmarshalJSON(data []byte) error {
+	raw := struct {
+		Buttons [][]json.RawMessage `json:"buttons"`
+	}{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	k.Buttons = make([][]ButtonInterface, 0, len(raw.Buttons))
+	for _, rawRow := range raw.Buttons {
+		row := make([]ButtonInterface, 0, len(rawRow))
+		for _, rawButton := range rawRow {
+			button, err := unmarshalButton(rawButton)
+			if err != nil {
+				return err
+			}
+			row = append(row, button)
+		}
+		k.Buttons = append(k.Buttons, row)
+	}
+
+	return nil
+}
+
+func unmarshalButton(data []byte) (ButtonInterface, error) {
+	base := Button{}
+	if err := json.Unmarshal(data, &base); err != nil {
+		return nil, err
+	}
+
+	var button ButtonInterface
+	var err error
+	switch base.Type {
+	case CALLBACK:
+		b := CallbackButton{}
+		err = json.Unmarshal(data, &b)
+		button = b
+	case LINK:
+		b := LinkButton{}
+		err = json.Unmarshal(data, &b)
+		button = b
+	case CONTACT:
+		b := RequestContactButton{}
+		err = json.Unmarshal(data, &b)
+		button = b
+	case GEOLOCATION:
+		b := RequestGeoLocationButton{}
+		err = json.Unmarshal(data, &b)
+		button = b
+	case OPEN_APP:
+		b := OpenAppButton{}
+		err = json.Unmarshal(data, &b)
+		button = b
+	default:
+		button = base
+	}
+
+	return button, err
+}
+
 // LinkButton is a button that, when clicked, follows the contained link.
 type LinkButton struct {
 	Button
@@ -576,6 +637,21 @@ type ShareAttachment struct {
 	Payload AttachmentPayload `json:"payload"`
 }
 
+// ShareAttachmentRequest attaches a link preview.
+type ShareAttachmentRequest struct {
+	AttachmentRequest
+	Payload ShareAttachmentPayload `json:"payload"`
+}
+
+func NewShareAttachmentRequest(payload ShareAttachmentPayload) *ShareAttachmentRequest {
+	return &ShareAttachmentRequest{Payload: payload, AttachmentRequest: AttachmentRequest{Type: AttachmentShare}}
+}
+
+type ShareAttachmentPayload struct {
+	Url   string `json:"url,omitempty"`   // URL attached to the message as a media preview
+	Token string `json:"token,omitempty"` // Attachment token
+}
+
 // SimpleQueryResult is an empty struct for simple query responses
 type SimpleQueryResult struct {
 	Success bool   `json:"success"`           // `true` if request was successful. `false` otherwise
@@ -695,6 +771,28 @@ func NewVideoAttachmentRequest(payload UploadedInfo) *VideoAttachmentRequest {
 	return &VideoAttachmentRequest{Payload: payload, AttachmentRequest: AttachmentRequest{Type: AttachmentVideo}}
 }
 
+// VideoAttachmentDetails contains playback URLs and metadata of the video attachment.
+// The API does not report the file size of the video, it is known only from the Content-Length of a download URL.
+type VideoAttachmentDetails struct {
+	Token     string                  `json:"token"`               // Video attachment token
+	Urls      *VideoUrls              `json:"urls,omitempty"`      // URLs to download or play video. Can be `null` if video is unavailable
+	Thumbnail *PhotoAttachmentPayload `json:"thumbnail,omitempty"` // Video thumbnail
+	Width     int                     `json:"width"`               // Video width
+	Height    int                     `json:"height"`              // Video height
+	Duration  int                     `json:"duration"`            // Video duration in seconds
+}
+
+// VideoUrls contains URLs of the video in available resolutions.
+type VideoUrls struct {
+	Mp41080 string `json:"mp4_1080,omitempty"` // Video URL in 1080p resolution, if available
+	Mp4720  string `json:"mp4_720,omitempty"`  // Video URL in 720 resolution, if available
+	Mp4480  string `json:"mp4_480,omitempty"`  // Video URL in 480 resolution, if available
+	Mp4360  string `json:"mp4_360,omitempty"`  // Video URL in 360 resolution, if available
+	Mp4240  string `json:"mp4_240,omitempty"`  // Video URL in 240 resolution, if available
+	Mp4144  string `json:"mp4_144,omitempty"`  // Video URL in 144 resolution, if available
+	Hls     string `json:"hls,omitempty"`      // Live streaming URL, if available
+}
+
 // Update represents different types of events that occurred in the chat.
 // See its inheritors for specific event types.
 type Update struct {
@@ -1,6 +1,10 @@
 package schemes
 
 import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strconv"
 	"testing"
 )
@@ -57,3 +61,85 @@ func TestMessageCreatedUpdate_GetCommand(t *testing.T) {
 	}
 
 }
+
+func TestRequestRoundTrip(t *testing.T) {
+	cases := []struct {
+		fixture string
+		value   any
+		decoded any
+	}{
+		{
+			fixture: "new_message_body.json",
+			value: &NewMessageBody{
+				Text: "<b>Привет</b>",
+				Attachments: []interface{}{
+					NewPhotoAttachmentRequest(PhotoAttachmentRequestPayload{Token: "PHOTO_TOKEN"}),
+					NewInlineKeyboardAttachmentRequest(Keyboard{Buttons: [][]ButtonInterface{{
+						CallbackButton{Button: Button{Type: CALLBACK, Text: "Да"}, Payload: "yes", Intent: POSITIVE},
+					}}}),
+				},
+				Link:   &NewMessageLink{Type: REPLY, Mid: "mid.0000000000000001"},
+				Format: "html",
+				Notify: true,
+			},
+			decoded: new(NewMessageBody),
+		},
+		{
+			fixture: "chat_patch.json",
+			value: &ChatPatch{
+				Icon:  &PhotoAttachmentRequestPayload{Url: "https://i.oneme.ru/i?r=ICON"},
+				Title: "Новое название",
+			},
+			decoded: new(ChatPatch),
+		},
+		{
+			fixture: "subscription_request_body.json",
+			value: &SubscriptionRequestBody{
+				Secret:      "webhook_secret-1",
+				Url:         "https://example.com/webhook",
+				UpdateTypes: []string{string(TypeMessageCreated), string(TypeMessageCallback)},
+				Version:     "1.2.5",
+			},
+			decoded: new(SubscriptionRequestBody),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.fixture, func(t *testing.T) {
+			fixture, err := os.ReadFile(filepath.Join("testdata", "requests", c.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			encoded, err := json.Marshal(c.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertSameJSON(t, fixture, encoded)
+
+			if err := json.Unmarshal(fixture, c.decoded); err != nil {
+				t.Fatal(err)
+			}
+			reencoded, err := json.Marshal(c.decoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertSameJSON(t, fixture, reencoded)
+		})
+	}
+}
+
+func assertSameJSON(t *testing.T, want, got []byte) {
+	t.Helper()
+
+	var wantValue, gotValue any
+	if err := json.Unmarshal(want, &wantValue); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(got, &gotValue); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(wantValue, gotValue) {
+		t.Errorf("JSON is %s, want %s", got, want)
+	}
+}
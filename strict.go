@@ -0,0 +1,242 @@
+package maxbot
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/pavmos/max-bot-api-client-go/schemes"
+)
+
+var (
+	rawMessageType  = reflect.TypeOf(json.RawMessage{})
+	messageBodyType = reflect.TypeOf(schemes.MessageBody{})
+)
+
+// UnknownField is a JSON field of a response or an update that has no counterpart in schemes.
+type UnknownField struct {
+	Type string // Go type missing the field, e.g. "schemes.Message"
+	Path string // Path of the field in the decoded document, e.g. "message.body.reactions" or "chats[].pinned"
+}
+
+// SetStrictDecoding enables the strict mode: fields of responses and updates unknown to schemes are reported
+// to the function after decoding, once per document, so it is noticeable when schemes falls behind the API.
+// Requests never fail because of unknown fields. Nil disables the mode.
+func (a *Api) SetStrictDecoding(report func(UnknownField)) {
+	a.client.unknownFields = report
+}
+
+// UnknownFieldCounter counts unknown fields, its Add method is suitable for SetStrictDecoding.
+// The zero value is ready to use.
+type UnknownFieldCounter struct {
+	mu     sync.Mutex
+	counts map[UnknownField]int64
+}
+
+// Add counts the field.
+func (c *UnknownFieldCounter) Add(field UnknownField) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.counts == nil {
+		c.counts = make(map[UnknownField]int64)
+	}
+	c.counts[field]++
+}
+
+// Counts returns a copy of the counters.
+func (c *UnknownFieldCounter) Counts() map[UnknownField]int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	counts := make(map[UnknownField]int64, len(c.counts))
+	for field, count := range c.counts {
+		counts[field] = count
+	}
+
+	return counts
+}
+
+// decode decodes the response body into the result, checking it for unknown fields in the strict mode.
+func (cl *client) decode(body io.Reader, result any) error {
+	if cl.unknownFields == nil {
+		return json.NewDecoder(body).Decode(result)
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, result); err != nil {
+		return err
+	}
+	cl.checkUnknownFields(data, result)
+
+	return nil
+}
+
+// checkUnknownFields reports the fields of data missing in the decoded result, if the strict mode is enabled.
+func (cl *client) checkUnknownFields(data []byte, result any) {
+	if cl.unknownFields == nil {
+		return
+	}
+
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return
+	}
+
+	checker := &fieldChecker{seen: make(map[UnknownField]bool)}
+	checker.walk(raw, reflect.ValueOf(result), "")
+	for _, field := range checker.fields {
+		cl.unknownFields(field)
+	}
+}
+
+type fieldChecker struct {
+	seen   map[UnknownField]bool
+	fields []UnknownField
+}
+
+// walk compares the raw JSON value with the decoded value. Dynamic types of interfaces come from the value,
+// so attachments and buttons are checked against their concrete types.
+func (c *fieldChecker) walk(raw any, v reflect.Value, path string) {
+	if raw == nil || !v.IsValid() {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			v = reflect.New(v.Type().Elem())
+		}
+		c.walk(raw, v.Elem(), path)
+	case reflect.Interface:
+		if !v.IsNil() {
+			c.walk(raw, v.Elem(), path)
+		}
+	case reflect.Struct:
+		object, ok := raw.(map[string]any)
+		if !ok {
+			return
+		}
+		c.walkStruct(object, v, path)
+	case reflect.Slice, reflect.Array:
+		items, ok := raw.([]any)
+		if !ok || v.Type() == rawMessageType {
+			return
+		}
+		for i, item := range items {
+			if i < v.Len() {
+				c.walk(item, v.Index(i), path+"[]")
+			}
+		}
+	case reflect.Map:
+		object, ok := raw.(map[string]any)
+		if !ok || v.Type().Key().Kind() != reflect.String {
+			return
+		}
+		for key, item := range object {
+			value := v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key()))
+			if !value.IsValid() {
+				value = reflect.New(v.Type().Elem()).Elem()
+			}
+			c.walk(item, value, joinPath(path, "*"))
+		}
+	}
+}
+
+func (c *fieldChecker) walkStruct(object map[string]any, v reflect.Value, path string) {
+	fields := jsonFields(v.Type())
+	for key, item := range object {
+		keyPath := joinPath(path, key)
+
+		if v.Type() == messageBodyType && key == "attachments" {
+			c.walkAttachments(item, keyPath)
+			continue
+		}
+
+		index, ok := fields[key]
+		if !ok {
+			for name, i := range fields {
+				if strings.EqualFold(name, key) {
+					index, ok = i, true
+					break
+				}
+			}
+		}
+		if !ok {
+			field := UnknownField{Type: v.Type().String(), Path: keyPath}
+			if !c.seen[field] {
+				c.seen[field] = true
+				c.fields = append(c.fields, field)
+			}
+			continue
+		}
+
+		c.walk(item, v.FieldByIndex(index), keyPath)
+	}
+}
+
+// walkAttachments checks raw attachments of a message against their concrete types, decoded or not.
+func (c *fieldChecker) walkAttachments(raw any, path string) {
+	items, ok := raw.([]any)
+	if !ok {
+		return
+	}
+
+	for _, item := range items {
+		object, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		attachmentType, _ := object["type"].(string)
+		constructor := getAttachmentType(schemes.AttachmentType(attachmentType))
+		if constructor == nil {
+			continue
+		}
+
+		attachment := constructor()
+		data, err := json.Marshal(object)
+		if err != nil || json.Unmarshal(data, attachment) != nil {
+			continue
+		}
+		c.walk(object, reflect.ValueOf(attachment), path+"[]")
+	}
+}
+
+// jsonFields returns the indexes of the struct fields by their JSON names, including fields of embedded structs.
+func jsonFields(t reflect.Type) map[string][]int {
+	fields := make(map[string][]int)
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if _, exists := fields[name]; !exists || len(field.Index) < len(fields[name]) {
+			fields[name] = field.Index
+		}
+	}
+
+	return fields
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
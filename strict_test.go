@@ -0,0 +1,84 @@
+package maxbot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStrictDecoding_Update(t *testing.T) {
+	api, err := New("test")
+	require.NoError(t, err)
+	counter := &UnknownFieldCounter{}
+	api.SetStrictDecoding(counter.Add)
+
+	data := []byte(`{
+		"update_type": "message_callback",
+		"timestamp": 1,
+		"user_locale": "ru",
+		"callback": {"callback_id": "c", "user": {"user_id": 1, "name": "a", "avatar": "x"}},
+		"message": {
+			"recipient": {"chat_id": 1, "chat_type": "dialog"},
+			"body": {
+				"mid": "mid.1",
+				"reactions": [],
+				"attachments": [
+					{"type": "image", "payload": {"photo_id": 1, "token": "t", "url": "u", "width": 10}},
+					{"type": "image", "payload": {"photo_id": 2, "token": "t", "url": "u", "width": 20}},
+					{"type": "inline_keyboard", "payload": {"buttons": [[{"type": "callback", "text": "a", "payload": "p", "color": "red"}]]}},
+					{"type": "poll", "question": "?"}
+				]
+			}
+		}
+	}`)
+	_, err = api.bytesToProperUpdate(data)
+	require.NoError(t, err)
+
+	require.Equal(t, map[UnknownField]int64{
+		{Type: "schemes.MessageCallbackUpdate", Path: "user_locale"}:                                   1,
+		{Type: "schemes.User", Path: "callback.user.avatar"}:                                           1,
+		{Type: "schemes.MessageBody", Path: "message.body.reactions"}:                                  1,
+		{Type: "schemes.PhotoAttachmentPayload", Path: "message.body.attachments[].payload.width"}:     1,
+		{Type: "schemes.CallbackButton", Path: "message.body.attachments[].payload.buttons[][].color"}: 1,
+	}, counter.Counts())
+
+	// Known fields only.
+	data, err = os.ReadFile("testdata/updates/message_removed.json")
+	require.NoError(t, err)
+	counter = &UnknownFieldCounter{}
+	api.SetStrictDecoding(counter.Add)
+	_, err = api.bytesToProperUpdate(data)
+	require.NoError(t, err)
+	require.Empty(t, counter.Counts())
+}
+
+func TestStrictDecoding_Response(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"chat_id": 1, "type": "chat", "status": "active", "title": "t", "last_event_time": 1, "participants_count": 2, "is_public": false, "messages_count": 5, "pinned_message": {"body": {"mid": "m"}}}`))
+	}))
+	defer server.Close()
+
+	api := newTestApi(t, server.URL)
+	var fields []UnknownField
+	api.SetStrictDecoding(func(field UnknownField) {
+		fields = append(fields, field)
+	})
+
+	chat, err := api.Chats.GetChat(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, "t", chat.Title)
+	require.ElementsMatch(t, []UnknownField{
+		{Type: "schemes.Chat", Path: "messages_count"},
+		{Type: "schemes.Chat", Path: "pinned_message"},
+	}, fields)
+
+	api.SetStrictDecoding(nil)
+	fields = nil
+	_, err = api.Chats.GetChat(context.Background(), 1)
+	require.NoError(t, err)
+	require.Empty(t, fields)
+}
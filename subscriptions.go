@@ -2,8 +2,6 @@ package maxbot
 
 import (
 	"context"
-	"encoding/json"
-	"log"
 	"net/http"
 	"net/url"
 
@@ -29,11 +27,11 @@ func (a *subscriptions) GetSubscriptions(ctx context.Context) (*schemes.GetSubsc
 	}
 	defer func() {
 		if err := body.Close(); err != nil {
-			log.Println(err)
+			a.client.logger.Error("failed to close response body", "error", err)
 		}
 	}()
 
-	return result, json.NewDecoder(body).Decode(result)
+	return result, a.client.decode(body, result)
 }
 
 // Subscribe subscribes the bot to receive updates via WebHook.
@@ -53,11 +51,11 @@ func (a *subscriptions) Subscribe(ctx context.Context, subscribeURL string, upda
 	}
 	defer func() {
 		if err := body.Close(); err != nil {
-			log.Println(err)
+			a.client.logger.Error("failed to close response body", "error", err)
 		}
 	}()
 
-	return result, json.NewDecoder(body).Decode(result)
+	return result, a.client.decode(body, result)
 }
 
 // Unsubscribe unsubscribes the bot from receiving updates via WebHook.
@@ -72,9 +70,9 @@ func (a *subscriptions) Unsubscribe(ctx context.Context, subscriptionURL string)
 	}
 	defer func() {
 		if err := body.Close(); err != nil {
-			log.Println(err)
+			a.client.logger.Error("failed to close response body", "error", err)
 		}
 	}()
 
-	return result, json.NewDecoder(body).Decode(result)
+	return result, a.client.decode(body, result)
 }
@@ -1,67 +1,81 @@
 package maxbot
 
 import (
-	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"io"
-	"log"
 	"mime"
 	"mime/multipart"
 	"net/http"
 	"net/url"
 	"os"
 	"strings"
+	"sync"
 
 	"github.com/pavmos/max-bot-api-client-go/schemes"
 )
 
+const (
+	uploadFormField       = "data"
+	defaultUploadFileName = "file"
+	maxErrorBodySize      = 64 << 10
+)
+
 type uploads struct {
 	client *client
+	cache  UploadCache
+
+	mu         sync.RWMutex
+	sizeLimits map[schemes.UploadType]int64
 }
 
 func newUploads(client *client) *uploads {
 	return &uploads{client: client}
 }
 
+func (a *uploads) setCache(cache UploadCache) {
+	a.cache = cache
+}
+
 // UploadMediaFromFile uploads the file to the Max server.
-func (a *uploads) UploadMediaFromFile(ctx context.Context, uploadType schemes.UploadType, filename string) (*schemes.UploadedInfo, error) {
+func (a *uploads) UploadMediaFromFile(ctx context.Context, uploadType schemes.UploadType, filename string, opts ...UploadOption) (*schemes.UploadedInfo, error) {
 	fh, err := os.Open(filename)
 	if err != nil {
 		return nil, err
 	}
 	defer fh.Close()
 
-	return a.UploadMediaFromReaderWithName(ctx, uploadType, fh, filename)
+	return a.UploadMediaFromReaderWithName(ctx, uploadType, fh, filename, opts...)
 }
 
 // UploadMediaFromUrl uploads the file from a remote server to the Max server.
-func (a *uploads) UploadMediaFromUrl(ctx context.Context, uploadType schemes.UploadType, u url.URL) (*schemes.UploadedInfo, error) {
-	respFile, err := http.Get(u.String())
+func (a *uploads) UploadMediaFromUrl(ctx context.Context, uploadType schemes.UploadType, u url.URL, opts ...UploadOption) (*schemes.UploadedInfo, error) {
+	respFile, err := a.client.fetch(ctx, u.String())
 	if err != nil {
 		return nil, err
 	}
 	defer respFile.Body.Close()
-	name := a.attachmentName(respFile)
+	name := attachmentName(respFile)
 
-	return a.UploadMediaFromReaderWithName(ctx, uploadType, respFile.Body, name)
+	return a.UploadMediaFromReaderWithName(ctx, uploadType, responseReader(respFile), name, opts...)
 }
 
-func (a *uploads) UploadMediaFromReader(ctx context.Context, uploadType schemes.UploadType, reader io.Reader) (*schemes.UploadedInfo, error) {
+func (a *uploads) UploadMediaFromReader(ctx context.Context, uploadType schemes.UploadType, reader io.Reader, opts ...UploadOption) (*schemes.UploadedInfo, error) {
 	result := new(schemes.UploadedInfo)
 
-	return result, a.uploadMediaFromReader(ctx, uploadType, reader, "", result)
+	return result, a.uploadMediaFromReader(ctx, uploadType, reader, "", result, opts...)
 }
 
-func (a *uploads) UploadMediaFromReaderWithName(ctx context.Context, uploadType schemes.UploadType, reader io.Reader, name string) (*schemes.UploadedInfo, error) {
+func (a *uploads) UploadMediaFromReaderWithName(ctx context.Context, uploadType schemes.UploadType, reader io.Reader, name string, opts ...UploadOption) (*schemes.UploadedInfo, error) {
 	result := new(schemes.UploadedInfo)
 
-	return result, a.uploadMediaFromReader(ctx, uploadType, reader, name, result)
+	return result, a.uploadMediaFromReader(ctx, uploadType, reader, name, result, opts...)
 }
 
 // UploadPhotoFromFile uploads photos to the Max server.
-func (a *uploads) UploadPhotoFromFile(ctx context.Context, fileName string) (*schemes.PhotoTokens, error) {
+func (a *uploads) UploadPhotoFromFile(ctx context.Context, fileName string, opts ...UploadOption) (*schemes.PhotoTokens, error) {
 	fh, err := os.Open(fileName)
 	if err != nil {
 		return nil, err
@@ -69,41 +83,41 @@ func (a *uploads) UploadPhotoFromFile(ctx context.Context, fileName string) (*sc
 	defer fh.Close()
 	result := new(schemes.PhotoTokens)
 
-	return result, a.uploadMediaFromReader(ctx, schemes.PHOTO, fh, fileName, result)
+	return result, a.uploadMediaFromReader(ctx, schemes.PHOTO, fh, fileName, result, opts...)
 }
 
 // UploadPhotoFromBase64String uploads photos to the Max server.
-func (a *uploads) UploadPhotoFromBase64String(ctx context.Context, code string) (*schemes.PhotoTokens, error) {
+func (a *uploads) UploadPhotoFromBase64String(ctx context.Context, code string, opts ...UploadOption) (*schemes.PhotoTokens, error) {
 	decoder := base64.NewDecoder(base64.StdEncoding, strings.NewReader(code))
 	result := new(schemes.PhotoTokens)
 
-	return result, a.uploadMediaFromReader(ctx, schemes.PHOTO, decoder, "", result)
+	return result, a.uploadMediaFromReader(ctx, schemes.PHOTO, decoder, "", result, opts...)
 }
 
 // UploadPhotoFromUrl uploads the photo from a remote server to the Max server.
-func (a *uploads) UploadPhotoFromUrl(ctx context.Context, url string) (*schemes.PhotoTokens, error) {
-	respFile, err := http.Get(url)
+func (a *uploads) UploadPhotoFromUrl(ctx context.Context, url string, opts ...UploadOption) (*schemes.PhotoTokens, error) {
+	respFile, err := a.client.fetch(ctx, url)
 	if err != nil {
 		return nil, err
 	}
 	defer respFile.Body.Close()
 	result := new(schemes.PhotoTokens)
-	name := a.attachmentName(respFile)
+	name := attachmentName(respFile)
 
-	return result, a.uploadMediaFromReader(ctx, schemes.PHOTO, respFile.Body, name, result)
+	return result, a.uploadMediaFromReader(ctx, schemes.PHOTO, responseReader(respFile), name, result, opts...)
 }
 
 // UploadPhotoFromReader uploads the photo from a reader.
-func (a *uploads) UploadPhotoFromReader(ctx context.Context, reader io.Reader) (*schemes.PhotoTokens, error) {
+func (a *uploads) UploadPhotoFromReader(ctx context.Context, reader io.Reader, opts ...UploadOption) (*schemes.PhotoTokens, error) {
 	result := new(schemes.PhotoTokens)
 
-	return result, a.uploadMediaFromReader(ctx, schemes.PHOTO, reader, "", result)
+	return result, a.uploadMediaFromReader(ctx, schemes.PHOTO, reader, "", result, opts...)
 }
 
-func (a *uploads) UploadPhotoFromReaderWithName(ctx context.Context, reader io.Reader, name string) (*schemes.PhotoTokens, error) {
+func (a *uploads) UploadPhotoFromReaderWithName(ctx context.Context, reader io.Reader, name string, opts ...UploadOption) (*schemes.PhotoTokens, error) {
 	result := new(schemes.PhotoTokens)
 
-	return result, a.uploadMediaFromReader(ctx, schemes.PHOTO, reader, name, result)
+	return result, a.uploadMediaFromReader(ctx, schemes.PHOTO, reader, name, result, opts...)
 }
 
 func (a *uploads) getUploadURL(ctx context.Context, uploadType schemes.UploadType) (*schemes.UploadEndpoint, error) {
@@ -116,11 +130,11 @@ func (a *uploads) getUploadURL(ctx context.Context, uploadType schemes.UploadTyp
 	}
 	defer func() {
 		if err := body.Close(); err != nil {
-			log.Println(err)
+			a.client.logger.Error("failed to close response body", "error", err)
 		}
 	}()
 
-	return result, json.NewDecoder(body).Decode(result)
+	return result, a.client.decode(body, result)
 }
 
 func (a *uploads) uploadMediaFromReader(
@@ -129,51 +143,222 @@ func (a *uploads) uploadMediaFromReader(
 	reader io.Reader,
 	fileName string,
 	result interface{},
+	opts ...UploadOption,
 ) error {
+	options := newUploadOptions(opts)
+	if options.cache == nil {
+		options.cache = a.cache
+	}
+
+	var cacheKey string
+	if seeker, ok := reader.(io.ReadSeeker); ok && options.cache != nil {
+		key, err := uploadCacheKey(a.client.key, uploadType, seeker)
+		if err != nil {
+			return err
+		}
+		if uploadType == schemes.PHOTO && options.image != nil {
+			key += options.image.cacheSuffix()
+		}
+		if cached, ok := options.cache.Get(key); ok {
+			if err := json.Unmarshal(cached, result); err == nil {
+				return nil
+			}
+		}
+		cacheKey = key
+	}
+
+	if uploadType == schemes.PHOTO && options.image != nil {
+		processed, name, err := options.image.process(reader, fileName)
+		if err != nil {
+			return err
+		}
+		reader, fileName = processed, name
+	}
+
+	size := readerSize(reader)
+	if !options.skipValidation {
+		head, peeked, err := peekHead(reader)
+		if err != nil {
+			return err
+		}
+		if err := a.validate(uploadType, head, fileName, size); err != nil {
+			return err
+		}
+		reader = peeked
+	}
+
 	endpoint, err := a.getUploadURL(ctx, uploadType)
 	if err != nil {
 		return err
 	}
-	bodyBuf := &bytes.Buffer{}
-	bodyWriter := multipart.NewWriter(bodyBuf)
 	if fileName == "" {
-		fileName = "file"
+		fileName = defaultUploadFileName
 	}
-	fileWriter, err := bodyWriter.CreateFormFile("data", fileName)
-	if err != nil {
-		return err
+
+	var progress *progressReader
+	if options.progress != nil {
+		progress = newProgressReader(ctx, reader, size, options)
+		reader = progress
 	}
-	_, err = io.Copy(fileWriter, reader)
+
+	body, contentType, contentLength, err := newMultipartBody(reader, fileName, size)
 	if err != nil {
 		return err
 	}
+	defer body.Close()
 
-	if err := bodyWriter.Close(); err != nil {
-		return err
-	}
-	contentType := bodyWriter.FormDataContentType()
-	if err := bodyWriter.Close(); err != nil {
-		return err
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.Url, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
 	}
+	req.Header.Set("Content-Type", contentType)
+	req.ContentLength = contentLength
 
-	resp, err := http.Post(endpoint.Url, contentType, bodyBuf)
+	resp, err := a.client.do(req, fmt.Sprintf("upload %s", uploadType))
 	if err != nil {
 		return err
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
-			log.Println(err)
+			a.client.logger.Error("failed to close response body", "error", err)
 		}
 	}()
 
-	if err = json.NewDecoder(resp.Body).Decode(result); err != nil {
-		return err
+	if resp.StatusCode != http.StatusOK {
+		return uploadError(resp.StatusCode, resp.Body)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return &NetworkError{Op: "read upload response", Err: err}
+	}
+	if err = json.Unmarshal(data, result); err != nil {
+		return &SerializationError{Op: "unmarshal", Type: "upload result", Err: err}
+	}
+	a.client.checkUnknownFields(data, result)
+	if progress != nil {
+		progress.done()
+	}
+
+	if cacheKey != "" {
+		if err := options.cache.Set(cacheKey, data); err != nil {
+			a.client.logger.Warn("failed to cache upload result", "error", err)
+		}
 	}
 
 	return nil
 }
 
-func (*uploads) attachmentName(r *http.Response) string {
+// uploadError converts an unsuccessful response of the upload endpoint into APIError.
+func uploadError(statusCode int, body io.Reader) error {
+	apiErr := &schemes.Error{}
+	if err := json.NewDecoder(io.LimitReader(body, maxErrorBodySize)).Decode(apiErr); err != nil || apiErr.Code == "" {
+		return &APIError{Code: statusCode, Message: http.StatusText(statusCode)}
+	}
+
+	return &APIError{
+		Code:    statusCode,
+		Message: apiErr.Code,
+		Details: apiErr.Message,
+	}
+}
+
+// newMultipartBody streams the reader as a multipart form file through a pipe, so the file is never held in memory.
+// Content length is -1 if the size of the reader is unknown (negative): the body is sent chunked then.
+func newMultipartBody(reader io.Reader, fileName string, size int64) (io.ReadCloser, string, int64, error) {
+	pr, pw := io.Pipe()
+	bodyWriter := multipart.NewWriter(pw)
+
+	contentLength := int64(-1)
+	if size >= 0 {
+		overhead, err := multipartOverhead(bodyWriter.Boundary(), fileName)
+		if err != nil {
+			return nil, "", 0, err
+		}
+		contentLength = overhead + size
+	}
+
+	go func() {
+		fileWriter, err := bodyWriter.CreateFormFile(uploadFormField, fileName)
+		if err == nil {
+			_, err = io.Copy(fileWriter, reader)
+		}
+		if err == nil {
+			err = bodyWriter.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	return pr, bodyWriter.FormDataContentType(), contentLength, nil
+}
+
+// multipartOverhead returns the size of the multipart headers and trailer surrounding the file content.
+func multipartOverhead(boundary string, fileName string) (int64, error) {
+	counter := &countingWriter{}
+	bodyWriter := multipart.NewWriter(counter)
+	if err := bodyWriter.SetBoundary(boundary); err != nil {
+		return 0, err
+	}
+	if _, err := bodyWriter.CreateFormFile(uploadFormField, fileName); err != nil {
+		return 0, err
+	}
+	if err := bodyWriter.Close(); err != nil {
+		return 0, err
+	}
+
+	return counter.n, nil
+}
+
+// readerSize returns the number of bytes left in the reader, or -1 if it cannot be known without reading.
+func readerSize(reader io.Reader) int64 {
+	switch r := reader.(type) {
+	case interface{ Len() int }:
+		return int64(r.Len())
+	case *os.File:
+		info, err := r.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return -1
+		}
+		offset, err := r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		return info.Size() - offset
+	}
+
+	return -1
+}
+
+// sizedReader is a reader of the known size, e.g. a response body with Content-Length.
+type sizedReader struct {
+	io.Reader
+	size int64
+}
+
+func (r *sizedReader) Len() int {
+	return int(r.size)
+}
+
+// responseReader returns the response body, keeping its size if the server reported it.
+func responseReader(resp *http.Response) io.Reader {
+	if resp.ContentLength < 0 {
+		return resp.Body
+	}
+
+	return &sizedReader{Reader: resp.Body, size: resp.ContentLength}
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+
+	return len(p), nil
+}
+
+func attachmentName(r *http.Response) string {
 	disposition := r.Header["Content-Disposition"]
 	if len(disposition) != 0 {
 		_, params, err := mime.ParseMediaType(disposition[0])
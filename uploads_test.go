@@ -0,0 +1,314 @@
+package maxbot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pavmos/max-bot-api-client-go/schemes"
+)
+
+// uploadServer is a local stand-in for the API and the upload endpoint. It discards uploaded content and remembers its size.
+type uploadServer struct {
+	*httptest.Server
+	uploads       int
+	received      int64
+	contentLength int64
+	fileName      string
+}
+
+func newUploadServer(t testing.TB) *uploadServer {
+	t.Helper()
+
+	s := &uploadServer{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/uploads", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(schemes.UploadEndpoint{Url: s.URL + "/upload"})
+	})
+	mux.HandleFunc("/upload", func(w http.ResponseWriter, r *http.Request) {
+		s.uploads++
+		s.contentLength = r.ContentLength
+
+		reader, err := r.MultipartReader()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		part, err := reader.NextPart()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.fileName = part.FileName()
+		s.received, err = io.Copy(io.Discard, part)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		json.NewEncoder(w).Encode(schemes.UploadedInfo{Token: "token" + strconv.FormatInt(s.received, 10)})
+	})
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func TestUploadMediaFromReader(t *testing.T) {
+	server := newUploadServer(t)
+	api := newTestApi(t, server.URL)
+
+	content := bytes.Repeat([]byte("0123456789"), 1000)
+	fileName := filepath.Join(t.TempDir(), "data.bin")
+	require.NoError(t, os.WriteFile(fileName, content, 0o600))
+
+	tests := []struct {
+		name      string
+		upload    func() (*schemes.UploadedInfo, error)
+		wantName  string
+		knownSize bool
+	}{
+		{
+			name: "sized reader",
+			upload: func() (*schemes.UploadedInfo, error) {
+				return api.Uploads.UploadMediaFromReaderWithName(context.Background(), schemes.FILE, bytes.NewReader(content), "data.bin")
+			},
+			wantName:  "data.bin",
+			knownSize: true,
+		},
+		{
+			name: "file",
+			upload: func() (*schemes.UploadedInfo, error) {
+				return api.Uploads.UploadMediaFromFile(context.Background(), schemes.FILE, fileName)
+			},
+			wantName:  fileName,
+			knownSize: true,
+		},
+		{
+			name: "unsized reader",
+			upload: func() (*schemes.UploadedInfo, error) {
+				return api.Uploads.UploadMediaFromReader(context.Background(), schemes.FILE, io.MultiReader(bytes.NewReader(content)))
+			},
+			wantName: defaultUploadFileName,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := tt.upload()
+			require.NoError(t, err)
+			require.Equal(t, "token"+strconv.Itoa(len(content)), info.Token)
+			require.Equal(t, int64(len(content)), server.received)
+			require.Equal(t, filepath.Base(tt.wantName), filepath.Base(server.fileName))
+			if tt.knownSize {
+				require.Greater(t, server.contentLength, int64(len(content)))
+			} else {
+				require.Equal(t, int64(-1), server.contentLength)
+			}
+		})
+	}
+}
+
+func TestUploadMediaFromReader_FlatMemory(t *testing.T) {
+	server := newUploadServer(t)
+	api := newTestApi(t, server.URL)
+
+	const size = 64 << 20
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	_, err := api.Uploads.UploadMediaFromReader(context.Background(), schemes.VIDEO, io.LimitReader(zeroReader{}, size))
+	require.NoError(t, err)
+
+	runtime.ReadMemStats(&after)
+	require.Equal(t, int64(size), server.received)
+	require.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(size/4), "upload must not buffer the whole file")
+}
+
+func BenchmarkUploadMediaFromReader(b *testing.B) {
+	server := newUploadServer(b)
+	api := newTestApi(b, server.URL)
+
+	const size = 16 << 20
+
+	b.ReportAllocs()
+	b.SetBytes(size)
+	for i := 0; i < b.N; i++ {
+		if _, err := api.Uploads.UploadMediaFromReader(context.Background(), schemes.VIDEO, io.LimitReader(zeroReader{}, size)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+
+	return len(p), nil
+}
+
+func TestUploadMediaFromReader_Errors(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("/uploads", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(schemes.UploadEndpoint{Url: server.URL + "/upload"})
+	})
+	mux.HandleFunc("/upload", func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		json.NewEncoder(w).Encode(schemes.Error{Code: "file.too.big", Message: "File is too big"})
+	})
+	mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+
+	api := newTestApi(t, server.URL)
+
+	t.Run("upload endpoint error", func(t *testing.T) {
+		_, err := api.Uploads.UploadMediaFromReader(context.Background(), schemes.FILE, bytes.NewReader([]byte("data")))
+		require.ErrorIs(t, err, &APIError{Code: http.StatusRequestEntityTooLarge})
+	})
+
+	t.Run("remote file error", func(t *testing.T) {
+		_, err := api.Uploads.UploadPhotoFromUrl(context.Background(), server.URL+"/missing")
+		var netErr *NetworkError
+		require.ErrorAs(t, err, &netErr)
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := api.Uploads.UploadMediaFromReader(ctx, schemes.FILE, bytes.NewReader([]byte("data")))
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestUploadMediaFromReader_Progress(t *testing.T) {
+	server := newUploadServer(t)
+	api := newTestApi(t, server.URL)
+
+	const size = 4 << 20
+
+	var reports []UploadProgress
+	_, err := api.Uploads.UploadMediaFromReaderWithName(context.Background(), schemes.VIDEO, bytes.NewReader(make([]byte, size)), "video.mp4",
+		WithProgress(func(p UploadProgress) { reports = append(reports, p) }),
+		WithProgressInterval(time.Nanosecond),
+	)
+	require.NoError(t, err)
+	require.NotEmpty(t, reports)
+
+	last := reports[len(reports)-1]
+	require.True(t, last.Done)
+	require.Equal(t, int64(size), last.Sent)
+	require.Equal(t, int64(size), last.Total)
+	require.Equal(t, float64(100), last.Percent())
+	for i := 1; i < len(reports); i++ {
+		require.GreaterOrEqual(t, reports[i].Sent, reports[i-1].Sent)
+	}
+}
+
+func TestUploadMediaFromReader_ProgressRejected(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	mux.HandleFunc("/uploads", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(schemes.UploadEndpoint{Url: server.URL + "/upload"})
+	})
+	mux.HandleFunc("/upload", func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	api := newTestApi(t, server.URL)
+
+	const size = 1 << 20
+
+	var reports []UploadProgress
+	_, err := api.Uploads.UploadMediaFromReaderWithName(context.Background(), schemes.VIDEO, bytes.NewReader(make([]byte, size)), "video.mp4",
+		WithProgress(func(p UploadProgress) { reports = append(reports, p) }),
+		WithProgressInterval(time.Nanosecond),
+	)
+	require.Error(t, err)
+	require.NotEmpty(t, reports)
+	require.Equal(t, int64(size), reports[len(reports)-1].Sent, "the source has been read completely")
+	for _, p := range reports {
+		require.False(t, p.Done, "the upload has not been accepted")
+	}
+}
+
+func TestUploadMediaFromReader_CancelMidTransfer(t *testing.T) {
+	server := newUploadServer(t)
+	api := newTestApi(t, server.URL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, err := api.Uploads.UploadMediaFromReader(ctx, schemes.VIDEO, io.LimitReader(zeroReader{}, 1<<30),
+		WithProgress(func(p UploadProgress) {
+			if p.Sent >= 1<<20 {
+				cancel()
+			}
+		}),
+		WithProgressInterval(time.Nanosecond),
+	)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestUploadCache(t *testing.T) {
+	server := newUploadServer(t)
+	api := newTestApi(t, server.URL)
+
+	fileName := filepath.Join(t.TempDir(), "logo.png")
+	require.NoError(t, os.WriteFile(fileName, pngHeader, 0o600))
+	cacheName := filepath.Join(t.TempDir(), "cache.json")
+
+	fileCache, err := NewFileUploadCache(cacheName, time.Hour)
+	require.NoError(t, err)
+	api.SetUploadCache(fileCache)
+
+	first, err := api.Uploads.UploadMediaFromFile(context.Background(), schemes.FILE, fileName)
+	require.NoError(t, err)
+	second, err := api.Uploads.UploadMediaFromFile(context.Background(), schemes.FILE, fileName)
+	require.NoError(t, err)
+	require.Equal(t, first, second)
+	require.Equal(t, 1, server.uploads)
+
+	_, err = api.Uploads.UploadMediaFromFile(context.Background(), schemes.PHOTO, fileName)
+	require.NoError(t, err)
+	require.Equal(t, 2, server.uploads, "the same content of another type must be uploaded")
+
+	reloaded, err := NewFileUploadCache(cacheName, time.Hour)
+	require.NoError(t, err)
+	_, err = api.Uploads.UploadMediaFromFile(context.Background(), schemes.FILE, fileName, WithUploadCache(reloaded))
+	require.NoError(t, err)
+	require.Equal(t, 2, server.uploads, "results must survive reloading the cache file")
+
+	expiring := NewMemoryUploadCache(time.Nanosecond)
+	for range 2 {
+		_, err = api.Uploads.UploadMediaFromFile(context.Background(), schemes.FILE, fileName, WithUploadCache(expiring))
+		require.NoError(t, err)
+	}
+	require.Equal(t, 4, server.uploads, "expired results must be uploaded again")
+
+	other := newTestApi(t, server.URL)
+	other.client.key = "other"
+	_, err = other.Uploads.UploadMediaFromFile(context.Background(), schemes.FILE, fileName, WithUploadCache(reloaded))
+	require.NoError(t, err)
+	require.Equal(t, 5, server.uploads, "results of another bot must not be reused")
+}